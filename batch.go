@@ -0,0 +1,73 @@
+package jsonrpc_client
+
+// DynBatch builds a batch of calls with different params and result types without
+// instantiating Invoke for each of them. Send it with Client.InvokeBatch(ctx, batch.Calls()).
+type DynBatch struct {
+	calls []MethodCaller
+}
+
+// NewDynBatch creates an empty DynBatch
+func NewDynBatch() *DynBatch {
+	return &DynBatch{}
+}
+
+// Add appends a call of method with params. The result is decoded into result, which must be
+// a pointer; a nil result discards it. Params set to nil or Omit are omitted from the request.
+func (b *DynBatch) Add(method string, params any, result any) *DynBatch {
+	b.calls = append(b.calls, &dynCall{method: method, params: params, result: result})
+	return b
+}
+
+// AddNotification appends a notification of method with params
+func (b *DynBatch) AddNotification(method string, params any) *DynBatch {
+	b.calls = append(b.calls, &dynCall{id: NewNotificationID(), method: method, params: params})
+	return b
+}
+
+// Len returns the number of calls in the batch
+func (b *DynBatch) Len() int {
+	return len(b.calls)
+}
+
+// Calls returns the calls of the batch in the order they were added
+func (b *DynBatch) Calls() []MethodCaller {
+	return b.calls
+}
+
+// dynCall is a MethodCaller whose result type is only known at runtime
+type dynCall struct {
+	id     *IDValue
+	method string
+	params any
+	result any
+}
+
+// JSONRPCRequest generates a JSON-RPC request
+func (d *dynCall) JSONRPCRequest() *JSONRPCRequest {
+	return &JSONRPCRequest{
+		Version: "2.0",
+		ID:      d.id,
+		Method:  d.method,
+		Params:  paramsOf(&d.params),
+	}
+}
+
+// Unmarshal decodes the result into the pointer given to Add and validates it if it
+// implements ResultValidator
+func (d *dynCall) Unmarshal(resp *JSONRPCResponse) error {
+	if d.result == nil {
+		return nil
+	}
+	if resp.missingResult() {
+		return &EmptyResultError{Method: d.method}
+	}
+	if err := resp.decodeResult(d.result); err != nil {
+		return &UnmarshalError{Method: d.method, Err: err}
+	}
+	if validator, ok := d.result.(ResultValidator); ok {
+		if err := validator.Validate(); err != nil {
+			return &ResultValidationError{Method: d.method, Err: err}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestDynBatch(t *testing.T) {
+	transport := &MockTransport{
+		SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			if !input.Batch {
+				t.Fatalf("expected a batch request")
+			}
+			var responses []*JSONRPCResponse
+			// Answer in reverse order to exercise ID based routing
+			for i := len(input.Requests) - 1; i >= 0; i-- {
+				req := input.Requests[i]
+				if req.ID.IsNotification() {
+					continue
+				}
+				resp := &JSONRPCResponse{Version: "2.0", ID: req.ID}
+				switch req.Method {
+				case "sum":
+					resp.Result = json.RawMessage(`6`)
+				case "user":
+					resp.Result = json.RawMessage(`{"name":"alice"}`)
+				case "tags":
+					resp.Result = json.RawMessage(`["a","b"]`)
+				case "fail":
+					resp.Error = &JSONRPCError{Code: -32000, Message: "failed"}
+				}
+				responses = append(responses, resp)
+			}
+			return &SendRequestOutput{Responses: responses}, nil
+		},
+	}
+
+	t.Run("mixed result types", func(t *testing.T) {
+		client := NewClient(transport)
+
+		var sum int
+		var user struct {
+			Name string `json:"name"`
+		}
+		var tags []string
+		batch := NewDynBatch().
+			Add("sum", []int{1, 2, 3}, &sum).
+			Add("user", map[string]any{"id": 1}, &user).
+			Add("tags", nil, &tags).
+			AddNotification("log", []string{"done"})
+
+		if batch.Len() != 4 {
+			t.Fatalf("expected 4 calls, got: %d", batch.Len())
+		}
+		if err := client.InvokeBatch(context.Background(), batch.Calls()); err != nil {
+			t.Fatalf("InvokeBatch error: %v", err)
+		}
+		if sum != 6 {
+			t.Errorf("expected sum: 6, got: %d", sum)
+		}
+		if user.Name != "alice" {
+			t.Errorf("expected name: alice, got: %s", user.Name)
+		}
+		if len(tags) != 2 || tags[0] != "a" || tags[1] != "b" {
+			t.Errorf("expected tags: [a b], got: %v", tags)
+		}
+	})
+
+	t.Run("errors are reported per call", func(t *testing.T) {
+		client := NewClient(transport)
+
+		var sum int
+		batch := NewDynBatch().
+			Add("sum", []int{1, 2, 3}, &sum).
+			Add("fail", nil, nil)
+
+		err := client.InvokeBatch(context.Background(), batch.Calls())
+
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) {
+			t.Fatalf("expected error type: *RPCError, got: %T", err)
+		}
+		if rpcErr.Method != "fail" {
+			t.Errorf("expected method: fail, got: %s", rpcErr.Method)
+		}
+		if sum != 6 {
+			t.Errorf("expected sum: 6, got: %d", sum)
+		}
+	})
+
+	t.Run("result type mismatch", func(t *testing.T) {
+		client := NewClient(transport)
+
+		var sum string
+		err := client.InvokeBatch(context.Background(), NewDynBatch().Add("sum", nil, &sum).Calls())
+
+		var unmarshalErr *UnmarshalError
+		if !errors.As(err, &unmarshalErr) {
+			t.Fatalf("expected error type: *UnmarshalError, got: %T", err)
+		}
+	})
+}
+
+func TestDynCallJSONRPCRequest(t *testing.T) {
+	t.Run("omit params", func(t *testing.T) {
+		calls := NewDynBatch().Add("ping", Omit{}, nil).Calls()
+		if params := calls[0].JSONRPCRequest().Params; params != nil {
+			t.Errorf("expected params to be omitted, got: %v", params)
+		}
+	})
+
+	t.Run("notification", func(t *testing.T) {
+		calls := NewDynBatch().AddNotification("log", "hello").Calls()
+		if !calls[0].JSONRPCRequest().ID.IsNotification() {
+			t.Error("expected a notification ID")
+		}
+	})
+}
@@ -0,0 +1,147 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Cache stores responses for CacheTransport
+type Cache interface {
+	// Get returns the cached response for key, if present and not expired
+	Get(key string) (*JSONRPCResponse, bool)
+	// Set stores resp under key for ttl
+	Set(key string, resp *JSONRPCResponse, ttl time.Duration)
+}
+
+// CacheKeyFunc returns the cache key for a request. Returning false bypasses the cache,
+// e.g. for methods that are not idempotent or params that contain volatile fields.
+type CacheKeyFunc func(req *JSONRPCRequest) (key string, ok bool)
+
+// DefaultCacheKey keys requests by method name and encoded params
+func DefaultCacheKey(req *JSONRPCRequest) (string, bool) {
+	params, err := json.Marshal(req.Params)
+	if err != nil {
+		return "", false
+	}
+	return req.Method + "\x00" + string(params), true
+}
+
+// CacheTransport serves repeated single requests from a cache instead of the network.
+// Only successful responses to calls are cached; notifications, error responses and
+// batch requests always go to the inner transport.
+type CacheTransport struct {
+	inner   Transport
+	cache   Cache
+	keyFunc CacheKeyFunc
+	ttl     time.Duration
+}
+
+// NewCacheTransport creates a CacheTransport. A nil keyFunc uses DefaultCacheKey.
+func NewCacheTransport(inner Transport, cache Cache, keyFunc CacheKeyFunc, ttl time.Duration) *CacheTransport {
+	if keyFunc == nil {
+		keyFunc = DefaultCacheKey
+	}
+	return &CacheTransport{
+		inner:   inner,
+		cache:   cache,
+		keyFunc: keyFunc,
+		ttl:     ttl,
+	}
+}
+
+// SendRequest returns a cached response when available, otherwise calls the inner transport
+func (t *CacheTransport) SendRequest(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+	if input.Batch || len(input.Requests) != 1 || input.Requests[0].ID.IsNotification() {
+		return t.inner.SendRequest(ctx, input)
+	}
+	request := input.Requests[0]
+	key, ok := t.keyFunc(request)
+	if !ok {
+		return t.inner.SendRequest(ctx, input)
+	}
+
+	if cached, hit := t.cache.Get(key); hit {
+		// Answer with the current request's ID
+		resp := *cached
+		resp.ID = request.ID
+		return &SendRequestOutput{Responses: []*JSONRPCResponse{&resp}}, nil
+	}
+
+	output, err := t.inner.SendRequest(ctx, input)
+	if err != nil {
+		return output, err
+	}
+	if output != nil && len(output.Responses) == 1 {
+		if resp := output.Responses[0]; resp != nil && resp.Error == nil && resp.Result != nil && resp.decodeErr == nil {
+			stored := *resp
+			t.cache.Set(key, &stored, t.ttl)
+		}
+	}
+	return output, nil
+}
+
+// memoryCacheSweepInterval is the minimum number of Set calls between sweeps of expired entries
+const memoryCacheSweepInterval = 128
+
+// MemoryCache is an in-memory Cache safe for concurrent use. Expired entries are removed when
+// they are read, and by a periodic sweep on Set, so keys that are never read again do not
+// accumulate.
+type MemoryCache struct {
+	mu      sync.Mutex
+	clock   Clock
+	entries map[string]memoryCacheEntry
+	// sets counts Set calls since the last sweep, which runs again after sweepAfter calls
+	sets       int
+	sweepAfter int
+}
+
+type memoryCacheEntry struct {
+	resp    *JSONRPCResponse
+	expires time.Time
+}
+
+// NewMemoryCache creates a MemoryCache. A nil clock uses the real time.
+func NewMemoryCache(clock Clock) *MemoryCache {
+	if clock == nil {
+		clock = realClock{}
+	}
+	return &MemoryCache{
+		clock:   clock,
+		entries: make(map[string]memoryCacheEntry),
+	}
+}
+
+// Get returns the cached response for key, removing it if it has expired
+func (c *MemoryCache) Get(key string) (*JSONRPCResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.clock.Now().Before(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.resp, true
+}
+
+// Set stores resp under key for ttl
+func (c *MemoryCache) Set(key string, resp *JSONRPCResponse, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.clock.Now()
+	if c.sets++; c.sets >= max(memoryCacheSweepInterval, c.sweepAfter) {
+		c.sets = 0
+		for k, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		// Waiting for as many calls as there are entries left keeps the cost per Set constant
+		c.sweepAfter = len(c.entries)
+	}
+	c.entries[key] = memoryCacheEntry{resp: resp, expires: now.Add(ttl)}
+}
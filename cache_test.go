@@ -0,0 +1,161 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yacchi/go-jsonrpc-client/testutil"
+)
+
+func TestCacheTransport(t *testing.T) {
+	var calls int
+	inner := &MockTransport{
+		SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			calls++
+			output := &SendRequestOutput{}
+			for _, request := range input.Requests {
+				if request.ID.IsNotification() {
+					continue
+				}
+				if request.Method == "fail" {
+					output.Responses = append(output.Responses, &JSONRPCResponse{ID: request.ID, Error: &JSONRPCError{Code: -32000, Message: "busy"}})
+					continue
+				}
+				output.Responses = append(output.Responses, &JSONRPCResponse{ID: request.ID, Result: json.RawMessage(`"value"`)})
+			}
+			return output, nil
+		},
+	}
+
+	setup := func(keyFunc CacheKeyFunc) (*Client, *testutil.FakeClock) {
+		calls = 0
+		clock := testutil.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		transport := NewCacheTransport(inner, NewMemoryCache(clock), keyFunc, time.Minute)
+		return NewClient(transport), clock
+	}
+	call := func(t *testing.T, client *Client, method string, params []int) (string, error) {
+		t.Helper()
+		invoke := &Invoke[[]int, string]{Name: method, Request: params}
+		err := client.Invoke(context.Background(), invoke)
+		return invoke.Response, err
+	}
+
+	t.Run("hit", func(t *testing.T) {
+		client, _ := setup(nil)
+		for i := 0; i < 3; i++ {
+			result, err := call(t, client, "get", []int{1})
+			if err != nil {
+				t.Fatalf("Invoke error: %v", err)
+			}
+			if result != "value" {
+				t.Errorf("expected result: value, got: %s", result)
+			}
+		}
+		if calls != 1 {
+			t.Errorf("expected 1 inner call, got: %d", calls)
+		}
+	})
+
+	t.Run("miss on different params", func(t *testing.T) {
+		client, _ := setup(nil)
+		call(t, client, "get", []int{1})
+		call(t, client, "get", []int{2})
+		if calls != 2 {
+			t.Errorf("expected 2 inner calls, got: %d", calls)
+		}
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		client, clock := setup(nil)
+		call(t, client, "get", []int{1})
+		clock.Advance(59 * time.Second)
+		call(t, client, "get", []int{1})
+		if calls != 1 {
+			t.Errorf("expected 1 inner call before expiry, got: %d", calls)
+		}
+		clock.Advance(time.Second)
+		call(t, client, "get", []int{1})
+		if calls != 2 {
+			t.Errorf("expected 2 inner calls after expiry, got: %d", calls)
+		}
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		client, _ := setup(nil)
+		call(t, client, "fail", nil)
+		call(t, client, "fail", nil)
+		if calls != 2 {
+			t.Errorf("expected 2 inner calls, got: %d", calls)
+		}
+	})
+
+	t.Run("notifications and batches bypass the cache", func(t *testing.T) {
+		client, _ := setup(nil)
+		notify := AsNotification(&Invoke[[]int, string]{Name: "get", Request: []int{1}})
+		client.Invoke(context.Background(), notify)
+		client.Invoke(context.Background(), notify)
+		batch := []MethodCaller{&Invoke[[]int, string]{Name: "get", Request: []int{1}}}
+		client.InvokeBatch(context.Background(), batch)
+		client.InvokeBatch(context.Background(), batch)
+		if calls != 4 {
+			t.Errorf("expected 4 inner calls, got: %d", calls)
+		}
+	})
+
+	t.Run("keyFunc can bypass", func(t *testing.T) {
+		client, _ := setup(func(req *JSONRPCRequest) (string, bool) {
+			return req.Method, req.Method != "now"
+		})
+		call(t, client, "now", nil)
+		call(t, client, "now", nil)
+		if calls != 2 {
+			t.Errorf("expected 2 inner calls, got: %d", calls)
+		}
+	})
+
+	t.Run("cached response carries the current ID", func(t *testing.T) {
+		client, _ := setup(nil)
+		call(t, client, "get", []int{1})
+
+		transport := client.transport
+		output, err := transport.SendRequest(context.Background(), &SendRequestInput{
+			Requests: []*JSONRPCRequest{{Version: "2.0", ID: NewID("next"), Method: "get", Params: []int{1}}},
+		})
+		if err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+		if !output.Responses[0].ID.Equal(NewID("next")) {
+			t.Errorf("expected ID: next, got: %v", output.Responses[0].ID)
+		}
+	})
+}
+
+func TestMemoryCacheEvictsExpiredEntries(t *testing.T) {
+	clock := testutil.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	cache := NewMemoryCache(clock)
+	resp := &JSONRPCResponse{Version: "2.0", Result: json.RawMessage(`1`)}
+
+	// Keys that are never read again must not accumulate once expired
+	for i := 0; i < 1000; i++ {
+		cache.Set(fmt.Sprintf("old-%d", i), resp, time.Minute)
+	}
+	clock.Advance(time.Minute)
+	for i := 0; i < 1000; i++ {
+		cache.Set(fmt.Sprintf("new-%d", i), resp, time.Hour)
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	for key := range cache.entries {
+		if strings.HasPrefix(key, "old-") {
+			t.Fatalf("expected expired entries to be evicted, found: %s", key)
+		}
+	}
+	if len(cache.entries) != 1000 {
+		t.Errorf("expected 1000 live entries, got: %d", len(cache.entries))
+	}
+}
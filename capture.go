@@ -0,0 +1,46 @@
+package jsonrpc_client
+
+import (
+	"bytes"
+	"io"
+)
+
+// maxCapturedResponseBytes bounds how much of a response body WithCaptureBytesOnError keeps
+const maxCapturedResponseBytes = 64 << 10
+
+// ByteDumper receives the raw bytes of a failed exchange: the request body as sent and the
+// response body as received, which is empty if no response arrived
+type ByteDumper func(request, response []byte, err error)
+
+// WithCaptureBytesOnError keeps the request and response bodies of each call and passes them
+// to dump only when SendRequest fails, e.g. with StatusCodeError or UnmarshalError, so they
+// can be inspected post-mortem. On success they are discarded without calling dump. Up to
+// 64KiB of the response body is kept. The slices are only valid during the call to dump.
+func WithCaptureBytesOnError(dump ByteDumper) HTTPTransportOption {
+	return func(t *HTTPTransport) {
+		t.captureOnError = dump
+	}
+}
+
+// limitedBuffer keeps the first limit bytes written to it and silently drops the rest
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); room > 0 {
+		b.Buffer.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
+
+// teeReadCloser copies what is read from a response body into a capture buffer
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func newTeeReadCloser(rc io.ReadCloser, w io.Writer) io.ReadCloser {
+	return teeReadCloser{Reader: io.TeeReader(rc, w), Closer: rc}
+}
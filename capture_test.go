@@ -0,0 +1,86 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPTransportCaptureBytesOnError(t *testing.T) {
+	status := http.StatusOK
+	reply := `{"jsonrpc":"2.0","id":1,"result":"ok"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(reply))
+	}))
+	defer server.Close()
+
+	var dumps int
+	var request, response string
+	var dumpErr error
+	transport := NewHTTPTransport(server.URL, WithCaptureBytesOnError(func(req, resp []byte, err error) {
+		dumps++
+		request, response, dumpErr = string(req), string(resp), err
+	}))
+	client := NewClient(transport)
+
+	t.Run("success is not dumped", func(t *testing.T) {
+		dumps = 0
+		invoke := &Invoke[[]int, string]{Name: "test", Request: []int{1}}
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if dumps != 0 {
+			t.Errorf("expected no dump, got: %d", dumps)
+		}
+	})
+
+	t.Run("status error", func(t *testing.T) {
+		dumps = 0
+		status, reply = http.StatusBadGateway, "upstream unavailable"
+		err := client.Invoke(context.Background(), &Invoke[[]int, string]{Name: "test", Request: []int{1}})
+
+		var statusErr *StatusCodeError
+		if !errors.As(err, &statusErr) {
+			t.Fatalf("expected error type: *StatusCodeError, got: %T", err)
+		}
+		if dumps != 1 {
+			t.Fatalf("expected 1 dump, got: %d", dumps)
+		}
+		if !strings.Contains(request, `"method":"test"`) {
+			t.Errorf("expected the request body, got: %s", request)
+		}
+		if response != reply {
+			t.Errorf("expected response: %s, got: %s", reply, response)
+		}
+		if !errors.As(dumpErr, &statusErr) {
+			t.Errorf("expected the dump to receive the error, got: %v", dumpErr)
+		}
+	})
+
+	t.Run("decode error", func(t *testing.T) {
+		dumps = 0
+		status, reply = http.StatusOK, `{"jsonrpc":"2.0","id":1,"result":`
+		err := client.Invoke(context.Background(), &Invoke[[]int, string]{Name: "test", Request: []int{1}})
+
+		var unmarshalErr *UnmarshalError
+		if !errors.As(err, &unmarshalErr) {
+			t.Fatalf("expected error type: *UnmarshalError, got: %T", err)
+		}
+		if dumps != 1 || response != reply {
+			t.Errorf("expected the truncated body to be dumped once, got: %d dumps of %s", dumps, response)
+		}
+	})
+
+	t.Run("response is bounded", func(t *testing.T) {
+		status, reply = http.StatusInternalServerError, strings.Repeat("x", maxCapturedResponseBytes+100)
+		_ = client.Invoke(context.Background(), &Invoke[[]int, string]{Name: "test", Request: []int{1}})
+
+		if len(response) != maxCapturedResponseBytes {
+			t.Errorf("expected %d captured bytes, got: %d", maxCapturedResponseBytes, len(response))
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package jsonrpc_client
+
+// Error codes defined by the JSON-RPC 2.0 specification
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// Category groups JSON-RPC error codes by their meaning in the specification, e.g. to label
+// error metrics
+type Category int
+
+const (
+	// CategoryApplication is any code outside the range reserved by the specification
+	CategoryApplication Category = iota
+	// CategoryParse is CodeParseError: the server could not parse the request JSON
+	CategoryParse
+	// CategoryInvalidRequest is CodeInvalidRequest: the request is not a valid request object
+	CategoryInvalidRequest
+	// CategoryMethodNotFound is CodeMethodNotFound
+	CategoryMethodNotFound
+	// CategoryInvalidParams is CodeInvalidParams
+	CategoryInvalidParams
+	// CategoryInternal is CodeInternalError
+	CategoryInternal
+	// CategoryServer is the implementation-defined server error range, -32099 to -32000
+	CategoryServer
+	// CategoryReserved is any other code in the reserved range, -32768 to -32000, which the
+	// specification does not define
+	CategoryReserved
+)
+
+// String returns a short name for the category, suitable as a metrics label
+func (c Category) String() string {
+	switch c {
+	case CategoryApplication:
+		return "application"
+	case CategoryParse:
+		return "parse"
+	case CategoryInvalidRequest:
+		return "invalid_request"
+	case CategoryMethodNotFound:
+		return "method_not_found"
+	case CategoryInvalidParams:
+		return "invalid_params"
+	case CategoryInternal:
+		return "internal"
+	case CategoryServer:
+		return "server"
+	case CategoryReserved:
+		return "reserved"
+	default:
+		return "unknown"
+	}
+}
+
+// ErrorCategory returns the category of a JSON-RPC error code
+func ErrorCategory(code int) Category {
+	switch {
+	case code == CodeParseError:
+		return CategoryParse
+	case code == CodeInvalidRequest:
+		return CategoryInvalidRequest
+	case code == CodeMethodNotFound:
+		return CategoryMethodNotFound
+	case code == CodeInvalidParams:
+		return CategoryInvalidParams
+	case code == CodeInternalError:
+		return CategoryInternal
+	case code >= -32099 && code <= -32000:
+		return CategoryServer
+	case code >= -32768 && code <= -32000:
+		return CategoryReserved
+	default:
+		return CategoryApplication
+	}
+}
+
+// Category returns the category of the error code
+func (e *RPCError) Category() Category {
+	return ErrorCategory(e.Code)
+}
@@ -0,0 +1,38 @@
+package jsonrpc_client
+
+import "testing"
+
+func TestErrorCategory(t *testing.T) {
+	tests := []struct {
+		code int
+		want Category
+	}{
+		{code: -32700, want: CategoryParse},
+		{code: -32600, want: CategoryInvalidRequest},
+		{code: -32601, want: CategoryMethodNotFound},
+		{code: -32602, want: CategoryInvalidParams},
+		{code: -32603, want: CategoryInternal},
+		{code: -32000, want: CategoryServer},
+		{code: -32099, want: CategoryServer},
+		{code: -32100, want: CategoryReserved},
+		{code: -32768, want: CategoryReserved},
+		{code: -32604, want: CategoryReserved},
+		{code: -32769, want: CategoryApplication},
+		{code: -31999, want: CategoryApplication},
+		{code: 0, want: CategoryApplication},
+		{code: 404, want: CategoryApplication},
+	}
+	for _, tt := range tests {
+		if got := ErrorCategory(tt.code); got != tt.want {
+			t.Errorf("code %d: expected category: %s, got: %s", tt.code, tt.want, got)
+		}
+	}
+
+	err := &RPCError{Method: "test", Code: CodeMethodNotFound}
+	if got := err.Category(); got != CategoryMethodNotFound {
+		t.Errorf("expected category: %s, got: %s", CategoryMethodNotFound, got)
+	}
+	if got := CategoryInvalidParams.String(); got != "invalid_params" {
+		t.Errorf("expected name: invalid_params, got: %s", got)
+	}
+}
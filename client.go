@@ -3,16 +3,69 @@ package jsonrpc_client
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"math"
+	"reflect"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 )
 
 // Client represents a JSON-RPC client
 type Client struct {
-	transport  Transport
-	generateId func() *IDValue
+	transport          Transport
+	generateId         func() *IDValue
+	stringIDs          bool
+	serverLimits       ServerLimits
+	requestMeta        func(method string) any
+	version            string
+	clock              Clock
+	emptyParams        ParamsPolicy
+	requestTransform   RequestTransform
+	responseTransform  ResponseTransform
+	validation         bool
+	noAutoID           bool
+	useNumber          bool
+	unwrapStringResult bool
+	decodeOnError      bool
+	emptyAsMissing     bool
+	dedupe             DedupeMode
+	timeout            time.Duration
+	methodTimeouts     map[string]time.Duration
+	deadlineParam      string
+
+	limiter              *semaphore
+	batchSlotsPerRequest bool
+
+	onUnexpected UnexpectedResponseHandler
+
+	logger   Logger
+	redactor Redactor
+
+	mu           sync.RWMutex
+	capabilities Capabilities
 }
 
+// ParamsPolicy controls how a request without params is serialized
+type ParamsPolicy int
+
+const (
+	// ParamsOmit drops the params member entirely (default)
+	ParamsOmit ParamsPolicy = iota
+	// ParamsEmptyObject sends "params": {}
+	ParamsEmptyObject
+	// ParamsEmptyArray sends "params": []
+	ParamsEmptyArray
+)
+
+// RequestTransform modifies a request after its ID is assigned and before it is sent
+type RequestTransform func(*JSONRPCRequest) error
+
+// ResponseTransform rewrites a response before it is checked for errors and decoded
+type ResponseTransform func(*JSONRPCResponse) (*JSONRPCResponse, error)
+
 // ClientOption is a function that configures a Client
 type ClientOption func(*Client)
 
@@ -25,22 +78,229 @@ func WithIDGenerator(generateId func() *IDValue) ClientOption {
 
 // WithSequenceIDGenerator sets a sequence-based ID generator for the client
 func WithSequenceIDGenerator() ClientOption {
-	var seq int
+	return WithSequenceIDGeneratorStep(1, 1)
+}
+
+// WithSequenceIDGeneratorStart sets a sequence-based ID generator that starts at start
+// instead of 1, so that clients sharing logs can use distinct ID ranges
+func WithSequenceIDGeneratorStart(start int) ClientOption {
+	return WithSequenceIDGeneratorStep(start, 1)
+}
+
+// WithSequenceIDGeneratorStep sets a sequence-based ID generator that starts at start and
+// advances by step, e.g. start 1 and 2 with step 2 give two clients disjoint sequences.
+// When the sequence would exceed math.MaxInt32 it wraps back to start. A step below 1 is treated
+// as 1, and one above math.MaxInt32-1 as math.MaxInt32-1. A start too close to math.MaxInt32 to
+// be followed by a second ID is replaced by 1, since the sequence would repeat the same ID.
+func WithSequenceIDGeneratorStep(start, step int) ClientOption {
+	step = min(max(step, 1), math.MaxInt32-1)
+	if start > math.MaxInt32-step {
+		start = 1
+	}
+	seq := start - step
 	var mu sync.Mutex
 	return WithIDGenerator(func() *IDValue {
 		mu.Lock()
 		defer mu.Unlock()
-		seq++
-		if seq > math.MaxInt32 {
-			seq = 1
+		if seq > math.MaxInt32-step {
+			seq = start
+		} else {
+			seq += step
 		}
 		return NewID(seq)
 	})
 }
 
-// AsNotification sets an Invoke to be sent as a notification (with null ID)
+// timeBasedIDsPerMilli is the number of IDs WithTimeBasedIDGenerator reserves per millisecond
+const timeBasedIDsPerMilli = 1000
+
+// WithTimeBasedIDGenerator sets an ID generator that produces monotonically increasing
+// integer IDs derived from the client's clock: the Unix time in milliseconds times 1000,
+// plus a counter for IDs generated within the same millisecond. Unlike the sequence
+// generator, IDs do not restart at 1 when the process restarts. The IDs stay below 2^53
+// until the year 2255, so servers that parse IDs as doubles echo them back exactly. On
+// platforms where int is 32 bits the IDs are sent as strings.
+func WithTimeBasedIDGenerator() ClientOption {
+	var last int64
+	var mu sync.Mutex
+	return func(c *Client) {
+		c.generateId = func() *IDValue {
+			mu.Lock()
+			defer mu.Unlock()
+			next := c.clock.Now().UnixMilli() * timeBasedIDsPerMilli
+			if next <= last {
+				next = last + 1
+			}
+			last = next
+			if strconv.IntSize == 32 {
+				return NewID(strconv.FormatInt(next, 10))
+			}
+			return NewID(int(next))
+		}
+	}
+}
+
+// WithFixedIDGenerator sets an ID generator that hands out ids in order, so tests can assert
+// the exact JSON of requests. It panics when the IDs are exhausted, which in a test points at
+// an unexpected extra request.
+func WithFixedIDGenerator(ids ...*IDValue) ClientOption {
+	next := 0
+	var mu sync.Mutex
+	return WithIDGenerator(func() *IDValue {
+		mu.Lock()
+		defer mu.Unlock()
+		if next >= len(ids) {
+			panic(fmt.Sprintf("fixed ID generator exhausted after %d IDs", len(ids)))
+		}
+		next++
+		return ids[next-1]
+	})
+}
+
+// WithStringIDs makes the client send generated integer IDs as strings, e.g. "id": "1"
+// instead of "id": 1, for servers that reject numeric IDs. It applies to whichever ID
+// generator is configured; IDs set explicitly on a request are sent as they are.
+func WithStringIDs() ClientOption {
+	return func(c *Client) {
+		c.stringIDs = true
+	}
+}
+
+// WithRequestMeta adds a non-standard "meta" member to the envelope of each request, next to
+// "params", set to what meta returns for the method. Nothing is added when meta returns nil
+// or the request already has Meta set.
+func WithRequestMeta(meta func(method string) any) ClientOption {
+	return func(c *Client) {
+		c.requestMeta = meta
+	}
+}
+
+// WithClock sets the clock used by time-dependent client features. It is mainly useful in tests.
+func WithClock(clock Clock) ClientOption {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
+
+// WithVersion sets the "jsonrpc" version string sent with every request.
+// An empty string omits the member entirely. The default is "2.0".
+func WithVersion(version string) ClientOption {
+	return func(c *Client) {
+		c.version = version
+	}
+}
+
+// WithEmptyParams sets how requests whose params are nil or Omit are serialized
+func WithEmptyParams(policy ParamsPolicy) ClientOption {
+	return func(c *Client) {
+		c.emptyParams = policy
+	}
+}
+
+// WithRequestTransform sets a function that is applied to every request, single or batch,
+// after its ID is assigned and before it is handed to the transport
+func WithRequestTransform(transform RequestTransform) ClientOption {
+	return func(c *Client) {
+		c.requestTransform = transform
+	}
+}
+
+// WithResponseTransform sets a function that is applied to every response, single or batch,
+// before it is decoded. It can be used to adapt servers that deviate slightly from the spec.
+func WithResponseTransform(transform ResponseTransform) ClientOption {
+	return func(c *Client) {
+		c.responseTransform = transform
+	}
+}
+
+// WithNoAutoID disables ID generation: a request without an ID is sent as a notification
+// instead of being assigned one. This suits proxies that forward pre-assigned IDs and must
+// never turn a notification into a call. It applies to Invoke and InvokeBatch. Call and
+// Initialize always need an ID to read the response, so under this mode they fail with
+// InvalidRequestError without sending anything.
+func WithNoAutoID() ClientOption {
+	return func(c *Client) {
+		c.noAutoID = true
+	}
+}
+
+// WithValidation enables checks that catch common mistakes before a request is sent:
+// an empty method name, a method name using the reserved "rpc." prefix, and duplicate IDs
+// within a batch. Violations are returned as InvalidRequestError.
+func WithValidation() ClientOption {
+	return func(c *Client) {
+		c.validation = true
+	}
+}
+
+// WithUseNumber decodes numbers in results into json.Number instead of float64, preserving
+// the precision of large integers and decimals. It only affects values decoded into an
+// interface, e.g. any, map[string]any or interface{} fields of Tout; typed numeric fields are
+// unaffected. It applies to Invoke, RawInvoke and DynBatch, and to other MethodCaller implementations
+// only if they decode the result themselves with UseNumber.
+func WithUseNumber() ClientOption {
+	return func(c *Client) {
+		c.useNumber = true
+	}
+}
+
+// UnexpectedResponseHandler receives responses a server sent to requests that expected none,
+// i.e. a single notification or a batch made only of notifications
+type UnexpectedResponseHandler func(requests []*JSONRPCRequest, responses []*JSONRPCResponse)
+
+// WithUnexpectedResponseHandler sets a handler that is called when a server replies to
+// notifications, which helps to debug servers that do so incorrectly. Without a handler such
+// replies are ignored. With HTTPTransport, acks like {"jsonrpc":"2.0","result":null} are
+// reported as well.
+func WithUnexpectedResponseHandler(handler UnexpectedResponseHandler) ClientOption {
+	return func(c *Client) {
+		c.onUnexpected = handler
+	}
+}
+
+// reportUnexpected passes any responses to notifications to the unexpected response handler
+func (c *Client) reportUnexpected(requests []*JSONRPCRequest, output *SendRequestOutput) {
+	if c.onUnexpected == nil || output == nil || len(output.Responses) == 0 {
+		return
+	}
+	c.onUnexpected(requests, output.Responses)
+}
+
+// WithUnwrapStringResult decodes results that a server double-encodes as a JSON string,
+// e.g. "result": "{\"x\":1}", by decoding the JSON inside the string. It only applies when
+// the string holds a JSON object or array and Tout is not a string or an interface, so
+// ordinary string results, including those decoded into types such as time.Time or []byte,
+// are left as they are.
+// Like WithUseNumber, it applies to Invoke, RawInvoke and DynBatch.
+func WithUnwrapStringResult() ClientOption {
+	return func(c *Client) {
+		c.unwrapStringResult = true
+	}
+}
+
+// WithDecodeResultOnError makes a call whose response carries both a result and an error,
+// which non-conformant servers sometimes send, still decode the result into the response
+// (e.g. Invoke.Response) before the RPCError is returned. A failure to decode the result is
+// ignored, since the RPCError is what the call reports.
+func WithDecodeResultOnError() ClientOption {
+	return func(c *Client) {
+		c.decodeOnError = true
+	}
+}
+
+// WithTreatEmptyObjectAsEmptyResult makes a result of {} or [] fail with EmptyResultError,
+// like a missing result, for servers that answer with an empty container when there is
+// nothing meaningful to return. By default such results decode into zero values. Like
+// WithUseNumber, it applies to Invoke, RawInvoke and DynBatch.
+func WithTreatEmptyObjectAsEmptyResult() ClientOption {
+	return func(c *Client) {
+		c.emptyAsMissing = true
+	}
+}
+
+// AsNotification sets an Invoke to be sent as a notification (without an id member)
 func AsNotification[Tin any, Tout any](invoke *Invoke[Tin, Tout]) *Invoke[Tin, Tout] {
-	invoke.ID = NewNullID()
+	invoke.ID = NewNotificationID()
 	return invoke
 }
 
@@ -48,6 +308,8 @@ func AsNotification[Tin any, Tout any](invoke *Invoke[Tin, Tout]) *Invoke[Tin, T
 func NewClient(transport Transport, opts ...ClientOption) *Client {
 	c := &Client{
 		transport: transport,
+		version:   "2.0",
+		clock:     realClock{},
 	}
 	for _, opt := range opts {
 		opt(c)
@@ -55,6 +317,16 @@ func NewClient(transport Transport, opts ...ClientOption) *Client {
 	if c.generateId == nil {
 		WithSequenceIDGenerator()(c)
 	}
+	if c.stringIDs {
+		generate := c.generateId
+		c.generateId = func() *IDValue {
+			id := generate()
+			if id != nil && id.intVar != nil {
+				return NewID(id.String())
+			}
+			return id
+		}
+	}
 	return c
 }
 
@@ -67,53 +339,183 @@ type MethodCaller interface {
 // Omit is used to indicate that a parameter should be omitted
 type Omit struct{}
 
-// Invoke represents method invocation information
+// OmitParams implements ParamsOmitter, so types embedding Omit are also omitted
+func (Omit) OmitParams() bool {
+	return true
+}
+
+// ParamsOmitter can be implemented by a request type to decide at runtime whether the
+// params member should be omitted. Requests that omit params also skip result decoding.
+type ParamsOmitter interface {
+	OmitParams() bool
+}
+
+// isOmit reports whether v should be treated as Omit
+func isOmit(v any) bool {
+	if o, ok := v.(ParamsOmitter); ok {
+		return o.OmitParams()
+	}
+	_, ok := v.(Omit)
+	return ok
+}
+
+// ParamsMarshaler can be implemented by a request type to send a different value as params,
+// e.g. a compact positional array, while the Go type stays convenient to fill in. An error
+// from MarshalParams is reported as MarshalError when the request is encoded.
+type ParamsMarshaler interface {
+	MarshalParams() (any, error)
+}
+
+// paramsOf returns the params to send for *v: nil when it is omitted, the MarshalParams
+// value when it or a pointer to it implements ParamsMarshaler, and *v itself otherwise
+func paramsOf[T any](v *T) any {
+	if isOmit(*v) {
+		return nil
+	}
+	marshaler, ok := any(*v).(ParamsMarshaler)
+	if !ok {
+		if marshaler, ok = any(v).(ParamsMarshaler); !ok {
+			return *v
+		}
+	}
+	params, err := marshaler.MarshalParams()
+	if err != nil {
+		return paramsError{err: err}
+	}
+	return params
+}
+
+// paramsError carries a MarshalParams error to the encoder
+type paramsError struct {
+	err error
+}
+
+func (p paramsError) MarshalJSON() ([]byte, error) {
+	return nil, p.err
+}
+
+// Invoke represents method invocation information.
+//
+// An Invoke can be reused for sequential calls, but not for concurrent ones. ID is owned by
+// the caller: the client never writes the generated ID back, so a nil ID gets a fresh one
+// on every call, while a fixed ID is sent as is each time. Response is reset to its zero
+// value when each call starts, so no data from an earlier call is left in it, even if the
+// call fails.
 type Invoke[Tin any, Tout any] struct {
 	ID       *IDValue
 	Name     string
 	Request  Tin
 	Response Tout
+	// Tag is opaque caller-side metadata, e.g. to correlate a call with the object it belongs
+	// to. It is not marshaled into the request, is carried on JSONRPCRequest.Tag, and is
+	// attached to errors of this call as TaggedError.
+	Tag any
 }
 
 // JSONRPCRequest generates a JSON-RPC request
 func (i *Invoke[Tin, Tout]) JSONRPCRequest() *JSONRPCRequest {
-	var params any
-	if _, isOmit := any(i.Request).(Omit); !isOmit {
-		params = i.Request
-	}
 	return &JSONRPCRequest{
 		Version: "2.0",
 		ID:      i.ID,
 		Method:  i.Name,
-		Params:  params,
+		Params:  paramsOf(&i.Request),
+		Tag:     i.Tag,
 	}
 }
 
-// Unmarshal decodes a JSON-RPC response
+// resetResponse clears the result of a previous call
+func (i *Invoke[Tin, Tout]) resetResponse() {
+	var zero Tout
+	i.Response = zero
+}
+
+// Unmarshal decodes a JSON-RPC response and validates the result if Tout implements ResultValidator
 func (i *Invoke[Tin, Tout]) Unmarshal(resp *JSONRPCResponse) error {
-	if _, isOmit := any(i.Request).(Omit); isOmit {
+	i.resetResponse()
+	if isOmit(i.Request) {
 		return nil
 	}
-	if resp.Result == nil {
+	if resp.missingResult() {
 		return &EmptyResultError{Method: i.Name}
 	}
-	if err := json.Unmarshal(resp.Result, &i.Response); err != nil {
+	if err := resp.decodeResult(&i.Response); err != nil {
 		return &UnmarshalError{Method: i.Name, Err: err}
 	}
+	return validateResult(i.Name, &i.Response)
+}
+
+// responseResetter is implemented by callers that keep the result of their last call
+type responseResetter interface {
+	resetResponse()
+}
+
+// resetResponse clears the result req kept from a previous call, if any
+func resetResponse(req MethodCaller) {
+	if r, ok := req.(responseResetter); ok {
+		r.resetResponse()
+	}
+}
+
+// ResultUnmarshaler can be implemented by a pointer to a result type to decode the raw
+// result itself instead of json.Unmarshal, e.g. for polymorphic or legacy result formats.
+// It receives the result exactly as received, so WithUseNumber and WithUnwrapStringResult do
+// not apply. An error it returns is reported as UnmarshalError.
+type ResultUnmarshaler interface {
+	UnmarshalResult(result json.RawMessage) error
+}
+
+// ResultValidator can be implemented by a result type to check the decoded result.
+// An error returned by Validate is reported as ResultValidationError.
+type ResultValidator interface {
+	Validate() error
+}
+
+// validateResult calls Validate on the decoded result if its type, or a pointer to it,
+// implements ResultValidator. A nil pointer or interface, e.g. from "result": null, is not
+// validated.
+func validateResult[T any](method string, result *T) error {
+	if v := reflect.ValueOf(result).Elem(); (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return nil
+	}
+	validator, ok := any(*result).(ResultValidator)
+	if !ok {
+		if validator, ok = any(result).(ResultValidator); !ok {
+			return nil
+		}
+	}
+	if err := validator.Validate(); err != nil {
+		return &ResultValidationError{Method: method, Err: err}
+	}
 	return nil
 }
 
-// Invoke calls a method
-func (c *Client) Invoke(ctx context.Context, req MethodCaller) error {
+// Invoke calls a method. When req is a notification (see AsNotification and WithNoAutoID),
+// Invoke returns nil as soon as the transport has sent it, without waiting for or reading a
+// response, and returns the transport error if sending fails.
+func (c *Client) Invoke(ctx context.Context, req MethodCaller) (err error) {
+	resetResponse(req)
+
 	// Get request information
 	request := req.JSONRPCRequest()
 
-	// Check if this is a notification request (ID is explicitly null)
-	isNotification := request.ID.IsExplicitlyNull()
+	// Record the request ID on the returned error for correlation with server logs
+	defer func() {
+		err = tagError(stampRequestID(err, request.ID), request.Tag)
+	}()
 
-	if request.ID == nil {
-		// Generate a new ID if ID is nil
-		request.ID = c.generateId()
+	// Check if this is a notification request (ID is present but has no value, or nil
+	// with WithNoAutoID). An explicitly null ID is sent as "id": null and still expects a response.
+	isNotification := request.ID.IsNotification() || (request.ID == nil && c.noAutoID)
+	if request.ID == nil && c.noAutoID {
+		request.ID = NewNotificationID()
+	}
+
+	if err := c.prepareRequest(request); err != nil {
+		return err
+	}
+
+	if err := c.validateRequests([]*JSONRPCRequest{request}); err != nil {
+		return err
 	}
 
 	// Send request
@@ -122,83 +524,136 @@ func (c *Client) Invoke(ctx context.Context, req MethodCaller) error {
 		Batch:    false,
 	}
 
-	output, err := c.transport.SendRequest(ctx, input)
+	ctx, cancel := c.withTimeout(ctx, input.Requests)
+	defer cancel()
+
+	output, err := c.send(ctx, input)
 	if err != nil {
 		return err // already wrapped in an appropriate error type
 	}
 
 	// For notification requests, no response is expected
 	if isNotification {
+		c.reportUnexpected(input.Requests, output)
 		return nil
 	}
 
-	if output == nil || len(output.Responses) == 0 {
+	if output == nil || len(output.Responses) == 0 || output.Responses[0] == nil {
 		return &EmptyResponseError{Method: request.Method}
 	}
 
-	response := output.Responses[0]
-
-	// Check JSON-RPC error
-	if response.Error != nil {
-		return &RPCError{
-			Method:  request.Method,
-			Code:    response.Error.Code,
-			Message: response.Error.Message,
-			Data:    response.Error.Data,
-		}
-	}
-
-	// Decode response
-	return req.Unmarshal(response)
+	return c.processResponse(req, request, output.Responses[0])
 }
 
 // InvokeBatch calls multiple methods in a batch
 func (c *Client) InvokeBatch(ctx context.Context, reqs []MethodCaller) error {
-	if len(reqs) == 0 {
-		return &InvalidRequestError{Message: "no requests provided"}
+	_, err := c.InvokeBatchWithIDs(ctx, reqs)
+	return err
+}
+
+// InvokeBatchWithIDs calls multiple methods in a batch like InvokeBatch and also returns the
+// ID of each request in request order, as sent on the wire, e.g. for audit logging.
+// Notifications have an ID without a value, and a call collapsed by WithDedupeBatch has the
+// ID of the request that was sent for it. The IDs are nil if the batch could not be built.
+func (c *Client) InvokeBatchWithIDs(ctx context.Context, reqs []MethodCaller) ([]*IDValue, error) {
+	return c.invokeBatch(ctx, reqs, nil)
+}
+
+// invokeBatch implements InvokeBatchWithIDs, calling onDone as each invoke completes if set
+func (c *Client) invokeBatch(ctx context.Context, reqs []MethodCaller, onDone ProgressFunc) ([]*IDValue, error) {
+	if err := checkBatchCallers(reqs); err != nil {
+		return nil, err
 	}
 
 	// Prepare requests
 	requests := make([]*JSONRPCRequest, len(reqs))
 	for i, req := range reqs {
+		resetResponse(req)
 		request := req.JSONRPCRequest()
-		// Generate ID if this is not a notification request (ID = nil)
-		if request.ID == nil {
-			// Generate ID for regular request
-			request.ID = c.generateId()
+		// Under WithNoAutoID a request without an ID is sent as a notification
+		if request.ID == nil && c.noAutoID {
+			request.ID = NewNotificationID()
+		}
+		if err := c.prepareRequest(request); err != nil {
+			return nil, err
 		}
 		requests[i] = request
 	}
 
+	if err := c.validateRequests(requests); err != nil {
+		return nil, err
+	}
+
+	sent, primary, err := c.dedupeBatch(requests)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.checkServerLimits(sent); err != nil {
+		return nil, err
+	}
+
+	ids := make([]*IDValue, len(requests))
+	for i := range requests {
+		ids[i] = requests[primary[i]].ID
+	}
+
 	// Send request
 	input := &SendRequestInput{
-		Requests: requests,
+		Requests: sent,
 		Batch:    true,
 	}
 
-	output, err := c.transport.SendRequest(ctx, input)
+	progress := newBatchProgress(c, reqs, requests, primary, onDone, input)
+	// Closed on every return, so that late deliveries cannot touch the invokes
+	defer progress.close()
+	if onDone != nil {
+		ctx = context.WithValue(ctx, progressKey{}, progress)
+	}
+
+	ctx, cancel := c.withTimeout(ctx, input.Requests)
+	defer cancel()
+
+	output, err := c.send(ctx, input)
 	if err != nil {
-		return err
+		return ids, err
+	}
+
+	// A notification-only batch expects no response, so whatever came back is not matched
+	if allNotifications(requests) {
+		c.reportUnexpected(input.Requests, output)
+		return ids, nil
 	}
 
 	// Process responses
-	if output == nil {
-		return &EmptyResponseError{Method: requests[0].Method}
+	if output == nil || len(output.Responses) == 0 {
+		for _, request := range requests {
+			if !request.ID.IsNotification() {
+				return ids, &EmptyResponseError{Method: request.Method}
+			}
+		}
 	}
 	// Map responses based on ID
 	responseMap := make(map[string]*JSONRPCResponse)
 	for _, resp := range output.Responses {
+		if resp == nil {
+			// A null element answers no request; its request is reported as missing
+			continue
+		}
 		if resp.ID != nil {
 			responseMap[resp.ID.String()] = resp
+		} else {
+			// "id": null decodes to a nil pointer; it answers an explicitly null ID
+			responseMap[NewNullID().String()] = resp
 		}
 	}
 
-	// Process response for each request
-	for i, req := range reqs {
-		request := requests[i]
-
-		// Check if this is a notification request (ID is explicitly null)
-		if request.ID.IsExplicitlyNull() {
+	// Process response for each request. Every invoke is processed even if an earlier
+	// one fails, so that successful responses are still decoded. Invokes already
+	// completed through DeliverResponse are skipped.
+	for i, request := range requests {
+		// Check if this is a notification request
+		if request.ID.IsNotification() {
 			// No response expected for notifications
 			continue
 		}
@@ -208,26 +663,290 @@ func (c *Client) InvokeBatch(ctx context.Context, reqs []MethodCaller) error {
 			continue
 		}
 
-		resp, ok := responseMap[request.ID.String()]
-		if !ok {
-			return &MissingResponseError{Method: request.Method}
+		// Duplicate calls collapsed by dedupeBatch are answered by their primary request;
+		// a nil response is reported as missing
+		progress.complete(i, responseMap[requests[primary[i]].ID.String()])
+	}
+
+	return ids, progress.close()
+}
+
+// checkBatchCallers rejects an empty batch and nil entries, which would otherwise panic when
+// the request is built
+func checkBatchCallers(reqs []MethodCaller) error {
+	if len(reqs) == 0 {
+		return &InvalidRequestError{Message: "no requests provided"}
+	}
+	first, nils := -1, 0
+	for i, req := range reqs {
+		if isNilCaller(req) {
+			if first < 0 {
+				first = i
+			}
+			nils++
 		}
+	}
+	switch {
+	case nils == len(reqs):
+		return &InvalidRequestError{Message: "batch contains only nil requests"}
+	case nils > 0:
+		return &InvalidRequestError{Message: fmt.Sprintf("request %d is nil", first)}
+	}
+	return nil
+}
+
+// isNilCaller reports whether req is nil or a nil pointer wrapped in the interface
+func isNilCaller(req MethodCaller) bool {
+	if req == nil {
+		return true
+	}
+	v := reflect.ValueOf(req)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
+
+// Call sends a single request and returns the raw response. Unlike Invoke, the result is not
+// decoded, the response transform is not applied, and a JSON-RPC error in the response is
+// returned as part of the response rather than as an RPCError.
+func (c *Client) Call(ctx context.Context, method string, params any) (resp *JSONRPCResponse, err error) {
+	if c.noAutoID {
+		return nil, errNoAutoID(method)
+	}
+	request := &JSONRPCRequest{
+		Method: method,
+		Params: params,
+	}
+
+	// Record the request ID on the returned error for correlation with server logs
+	defer func() {
+		stampRequestID(err, request.ID)
+	}()
+
+	if err := c.prepareRequest(request); err != nil {
+		return nil, err
+	}
+	if err := c.validateRequests([]*JSONRPCRequest{request}); err != nil {
+		return nil, err
+	}
 
-		// Check for JSON-RPC error
-		if resp.Error != nil {
-			return &RPCError{
-				Method:  request.Method,
-				Code:    resp.Error.Code,
-				Message: resp.Error.Message,
-				Data:    resp.Error.Data,
+	input := &SendRequestInput{
+		Requests: []*JSONRPCRequest{request},
+		Batch:    false,
+	}
+
+	ctx, cancel := c.withTimeout(ctx, input.Requests)
+	defer cancel()
+
+	output, err := c.send(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if output == nil || len(output.Responses) == 0 || output.Responses[0] == nil {
+		return nil, &EmptyResponseError{Method: method}
+	}
+	return output.Responses[0], nil
+}
+
+// SendRaw sends already-built requests, e.g. from a proxy or a replay tool, and returns the
+// responses as received. The requests are copied and sent with their own IDs, version and
+// params; only the request transform is applied to the copies. Timeouts, the concurrency
+// limit and logging apply as for Invoke. Responses go through the response transform, but
+// JSON-RPC errors are not converted and results are not decoded. Unless batch is set, reqs
+// must hold exactly one request.
+func (c *Client) SendRaw(ctx context.Context, reqs []*JSONRPCRequest, batch bool) ([]*JSONRPCResponse, error) {
+	if len(reqs) == 0 {
+		return nil, &InvalidRequestError{Message: "no requests provided"}
+	}
+	if !batch && len(reqs) > 1 {
+		return nil, &InvalidRequestError{Message: "only a batch can hold more than one request"}
+	}
+
+	requests := make([]*JSONRPCRequest, len(reqs))
+	for i, req := range reqs {
+		if req == nil {
+			return nil, &InvalidRequestError{Message: fmt.Sprintf("request %d is nil", i)}
+		}
+		request := *req
+		if c.requestTransform != nil {
+			if err := c.requestTransform(&request); err != nil {
+				return nil, &MarshalError{Method: req.Method, Err: err}
 			}
 		}
+		requests[i] = &request
+	}
+
+	input := &SendRequestInput{
+		Requests: requests,
+		Batch:    batch,
+	}
+
+	ctx, cancel := c.withTimeout(ctx, input.Requests)
+	defer cancel()
+
+	output, err := c.send(ctx, input)
+	if err != nil || output == nil {
+		return nil, err
+	}
+
+	responses := make([]*JSONRPCResponse, 0, len(output.Responses))
+	for _, resp := range output.Responses {
+		if resp == nil {
+			continue
+		}
+		transformed, err := c.transformResponse(methodFor(requests, resp.ID), resp)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, transformed)
+	}
+	return responses, nil
+}
+
+// methodFor returns the method of the request with the given ID, or of the first request if
+// none matches
+func methodFor(requests []*JSONRPCRequest, id *IDValue) string {
+	for _, request := range requests {
+		if id != nil && request.ID != nil && request.ID.Equal(id) {
+			return request.Method
+		}
+	}
+	return requests[0].Method
+}
+
+// processResponse turns the response to request into the result of req: it applies the
+// response transform, converts a JSON-RPC error into an RPCError and decodes the result.
+// It is shared by Invoke and InvokeBatch so both handle responses identically.
+func (c *Client) processResponse(req MethodCaller, request *JSONRPCRequest, resp *JSONRPCResponse) error {
+	if resp == nil {
+		return &EmptyResponseError{Method: request.Method, ID: request.ID}
+	}
+	if resp.sendErr != nil {
+		return resp.sendErr
+	}
+	if resp.decodeErr != nil {
+		return &UnmarshalError{Method: request.Method, Err: resp.decodeErr}
+	}
+
+	resp, err := c.transformResponse(request.Method, resp)
+	if err != nil {
+		return err
+	}
+
+	// Check JSON-RPC error
+	if err := resp.AsError(request.Method); err != nil {
+		if c.decodeOnError && resp.Result != nil {
+			_ = c.unmarshal(req, resp)
+		}
+		return err
+	}
+
+	// Decode response
+	return c.unmarshal(req, resp)
+}
+
+// unmarshal decodes resp into req, applying the client's decoding options. The response is
+// copied first because it may be shared, e.g. by collapsed batch requests or a cache.
+func (c *Client) unmarshal(req MethodCaller, resp *JSONRPCResponse) error {
+	if c.useNumber || c.unwrapStringResult || c.emptyAsMissing {
+		decoded := *resp
+		decoded.useNumber = c.useNumber
+		decoded.unwrapString = c.unwrapStringResult
+		decoded.emptyAsMissing = c.emptyAsMissing
+		resp = &decoded
+	}
+	return req.Unmarshal(resp)
+}
+
+// Notify sends params to method as a notification. No response is expected; acks some
+// servers send anyway are tolerated by the HTTP transport.
+func Notify[Tin any](ctx context.Context, c *Client, method string, params Tin) error {
+	return c.Invoke(ctx, AsNotification(&Invoke[Tin, Omit]{Name: method, Request: params}))
+}
+
+// joinErrors returns nil for no errors, the error itself for one, and errors.Join otherwise
+func joinErrors(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return errors.Join(errs...)
+	}
+}
+
+// errNoAutoID reports that method needs a generated ID, which WithNoAutoID disables
+func errNoAutoID(method string) error {
+	return &InvalidRequestError{Message: fmt.Sprintf("%s needs a generated ID, which WithNoAutoID disables", method)}
+}
+
+// prepareRequest assigns an ID and version, applies the empty params policy and runs the request transform
+func (c *Client) prepareRequest(request *JSONRPCRequest) error {
+	request.Version = c.version
+
+	// Generate ID if this is not a notification request (ID = nil). Callers that honor
+	// WithNoAutoID have already marked such requests as notifications.
+	if request.ID == nil {
+		request.ID = c.generateId()
+	}
+
+	if request.Params == nil {
+		switch c.emptyParams {
+		case ParamsEmptyObject:
+			request.Params = json.RawMessage(`{}`)
+		case ParamsEmptyArray:
+			request.Params = json.RawMessage(`[]`)
+		}
+	}
 
-		// Decode response
-		if err := req.Unmarshal(resp); err != nil {
-			return err
+	if c.requestMeta != nil && request.Meta == nil {
+		request.Meta = c.requestMeta(request.Method)
+	}
+
+	if c.requestTransform != nil {
+		method := request.Method
+		if err := c.requestTransform(request); err != nil {
+			return &MarshalError{Method: method, Err: err}
 		}
 	}
+	return nil
+}
 
+// validateRequests checks requests for mistakes when validation is enabled
+func (c *Client) validateRequests(requests []*JSONRPCRequest) error {
+	if !c.validation {
+		return nil
+	}
+	seen := make(map[string]int, len(requests))
+	for i, request := range requests {
+		if request.Method == "" {
+			return &InvalidRequestError{Message: fmt.Sprintf("request %d has an empty method name", i)}
+		}
+		if strings.HasPrefix(request.Method, "rpc.") {
+			return &InvalidRequestError{Message: fmt.Sprintf("method %q uses the reserved \"rpc.\" prefix", request.Method)}
+		}
+		if request.ID.IsNotification() {
+			continue
+		}
+		key := request.ID.String()
+		if j, ok := seen[key]; ok {
+			return &InvalidRequestError{Message: fmt.Sprintf("requests %d and %d share the same ID %s", j, i, key)}
+		}
+		seen[key] = i
+	}
 	return nil
 }
+
+// transformResponse applies the configured response transform, if any
+func (c *Client) transformResponse(method string, resp *JSONRPCResponse) (*JSONRPCResponse, error) {
+	if c.responseTransform == nil {
+		return resp, nil
+	}
+	transformed, err := c.responseTransform(resp)
+	if err != nil {
+		return nil, &UnmarshalError{Method: method, Err: err}
+	}
+	if transformed == nil {
+		return nil, &EmptyResponseError{Method: method}
+	}
+	return transformed, nil
+}
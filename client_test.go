@@ -4,10 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/yacchi/go-jsonrpc-client/testutil"
 )
 
 // MockTransport is a mock transport for testing
@@ -198,6 +205,70 @@ func TestWithSequenceIDGenerator(t *testing.T) {
 }
 
 // TestInvoke tests the Invoke method
+func TestWithSequenceIDGeneratorStep(t *testing.T) {
+	collect := func(client *Client, n int) []int {
+		ids := make([]int, n)
+		for i := range ids {
+			ids[i] = client.generateId().Value().(int)
+		}
+		return ids
+	}
+
+	t.Run("custom start", func(t *testing.T) {
+		client := NewClient(&MockTransport{}, WithSequenceIDGeneratorStart(1000))
+		got := collect(client, 3)
+		if !reflect.DeepEqual(got, []int{1000, 1001, 1002}) {
+			t.Errorf("expected IDs: [1000 1001 1002], got: %v", got)
+		}
+	})
+
+	t.Run("disjoint sequences", func(t *testing.T) {
+		odd := NewClient(&MockTransport{}, WithSequenceIDGeneratorStep(1, 2))
+		even := NewClient(&MockTransport{}, WithSequenceIDGeneratorStep(2, 2))
+		if got := collect(odd, 3); !reflect.DeepEqual(got, []int{1, 3, 5}) {
+			t.Errorf("expected IDs: [1 3 5], got: %v", got)
+		}
+		if got := collect(even, 3); !reflect.DeepEqual(got, []int{2, 4, 6}) {
+			t.Errorf("expected IDs: [2 4 6], got: %v", got)
+		}
+	})
+
+	t.Run("overflow wraps to start", func(t *testing.T) {
+		start := math.MaxInt32 - 2
+		client := NewClient(&MockTransport{}, WithSequenceIDGeneratorStart(start))
+		got := collect(client, 4)
+		expected := []int{start, start + 1, math.MaxInt32, start}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected IDs: %v, got: %v", expected, got)
+		}
+	})
+
+	t.Run("overflow with step", func(t *testing.T) {
+		client := NewClient(&MockTransport{}, WithSequenceIDGeneratorStep(math.MaxInt32-3, 2))
+		got := collect(client, 3)
+		expected := []int{math.MaxInt32 - 3, math.MaxInt32 - 1, math.MaxInt32 - 3}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected IDs: %v, got: %v", expected, got)
+		}
+	})
+
+	t.Run("start without room for a second ID falls back to 1", func(t *testing.T) {
+		tests := []struct {
+			start, step int
+			expected    []int
+		}{
+			{start: math.MaxInt32 - 1, step: 5, expected: []int{1, 6, 11}},
+			{start: math.MaxInt32, step: 1, expected: []int{1, 2, 3}},
+		}
+		for _, tt := range tests {
+			client := NewClient(&MockTransport{}, WithSequenceIDGeneratorStep(tt.start, tt.step))
+			if got := collect(client, 3); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("start %d, step %d: expected IDs: %v, got: %v", tt.start, tt.step, tt.expected, got)
+			}
+		}
+	})
+}
+
 func TestInvoke(t *testing.T) {
 	t.Run("successful case", func(t *testing.T) {
 		// Set up mock transport
@@ -1027,6 +1098,57 @@ func TestInvokeBatch(t *testing.T) {
 		if !errors.As(err, &invalidErr) {
 			t.Fatalf("expected error type: *InvalidRequestError, got: %T", err)
 		}
+		if invalidErr.Message != "no requests provided" {
+			t.Errorf("expected message: no requests provided, got: %s", invalidErr.Message)
+		}
+	})
+
+	t.Run("with only nil requests", func(t *testing.T) {
+		client := NewClient(&MockTransport{})
+
+		var typedNil *Invoke[[]int, string]
+		err := client.InvokeBatch(context.Background(), []MethodCaller{nil, typedNil})
+
+		var invalidErr *InvalidRequestError
+		if !errors.As(err, &invalidErr) {
+			t.Fatalf("expected error type: *InvalidRequestError, got: %T", err)
+		}
+		if invalidErr.Message != "batch contains only nil requests" {
+			t.Errorf("expected message: batch contains only nil requests, got: %s", invalidErr.Message)
+		}
+	})
+
+	t.Run("with a nil element", func(t *testing.T) {
+		client := NewClient(&MockTransport{
+			SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+				t.Error("expected nothing to be sent")
+				return nil, nil
+			},
+		})
+
+		invoke := &Invoke[[]int, string]{Name: "test.method", Request: []int{1}}
+		err := client.InvokeBatch(context.Background(), []MethodCaller{invoke, nil})
+
+		var invalidErr *InvalidRequestError
+		if !errors.As(err, &invalidErr) {
+			t.Fatalf("expected error type: *InvalidRequestError, got: %T", err)
+		}
+		if invalidErr.Message != "request 1 is nil" {
+			t.Errorf("expected message: request 1 is nil, got: %s", invalidErr.Message)
+		}
+	})
+
+	t.Run("with a typed nil element", func(t *testing.T) {
+		client := NewClient(&MockTransport{})
+
+		var typedNil *Invoke[[]int, string]
+		invoke := &Invoke[[]int, string]{Name: "test.method", Request: []int{1}}
+		err := client.InvokeBatch(context.Background(), []MethodCaller{invoke, invoke, typedNil, nil})
+
+		expected := "rpc: invalid request: request 2 is nil"
+		if err == nil || err.Error() != expected {
+			t.Errorf("expected error: %s, got: %v", expected, err)
+		}
 	})
 
 	t.Run("transport error in InvokeBatch", func(t *testing.T) {
@@ -1188,9 +1310,9 @@ func TestAsNotification(t *testing.T) {
 				}
 				request := input.Requests[0]
 
-				// Verify that the request ID is explicitly null
-				if request.ID == nil || !request.ID.IsExplicitlyNull() {
-					t.Errorf("expected ID to be explicitly null, got: %v", request.ID)
+				// Verify that the request ID marks a notification
+				if !request.ID.IsNotification() {
+					t.Errorf("expected ID to mark a notification, got: %v", request.ID)
 				}
 
 				// No response expected for notifications
@@ -1235,9 +1357,9 @@ func TestAsNotification(t *testing.T) {
 					return nil, errors.New("invalid request count")
 				}
 
-				// Verify that the first request ID is explicitly null
-				if input.Requests[0].ID == nil || !input.Requests[0].ID.IsExplicitlyNull() {
-					t.Errorf("expected first request ID to be explicitly null, got: %v", input.Requests[0].ID)
+				// Verify that the first request ID marks a notification
+				if !input.Requests[0].ID.IsNotification() {
+					t.Errorf("expected first request ID to mark a notification, got: %v", input.Requests[0].ID)
 				}
 
 				// Set response for the second request only
@@ -1445,3 +1567,2301 @@ func TestUnmarshal(t *testing.T) {
 		}
 	})
 }
+
+// TestWithResponseTransform tests the WithResponseTransform option
+func TestWithResponseTransform(t *testing.T) {
+	type TestResponse struct {
+		Result string `json:"result"`
+	}
+
+	// unwrapData extracts the result from a {"data": ...} envelope
+	unwrapData := func(resp *JSONRPCResponse) (*JSONRPCResponse, error) {
+		var envelope struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(resp.Result, &envelope); err != nil {
+			return nil, err
+		}
+		resp.Result = envelope.Data
+		return resp, nil
+	}
+
+	transport := &MockTransport{
+		SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			output := &SendRequestOutput{}
+			for _, request := range input.Requests {
+				output.Responses = append(output.Responses, &JSONRPCResponse{
+					ID:     request.ID,
+					Result: json.RawMessage(`{"data":{"result":"` + request.Method + `"}}`),
+				})
+			}
+			return output, nil
+		},
+	}
+
+	t.Run("single request", func(t *testing.T) {
+		client := NewClient(transport, WithResponseTransform(unwrapData))
+
+		invoke := &Invoke[map[string]string, TestResponse]{Name: "test.method", Request: map[string]string{}}
+
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if invoke.Response.Result != "test.method" {
+			t.Errorf("expected result: test.method, got: %s", invoke.Response.Result)
+		}
+	})
+
+	t.Run("batch request", func(t *testing.T) {
+		client := NewClient(transport, WithResponseTransform(unwrapData))
+
+		invoke1 := &Invoke[map[string]string, TestResponse]{Name: "test.method1", Request: map[string]string{}}
+		invoke2 := &Invoke[map[string]string, TestResponse]{Name: "test.method2", Request: map[string]string{}}
+
+		if err := client.InvokeBatch(context.Background(), []MethodCaller{invoke1, invoke2}); err != nil {
+			t.Fatalf("InvokeBatch error: %v", err)
+		}
+		if invoke1.Response.Result != "test.method1" {
+			t.Errorf("expected result1: test.method1, got: %s", invoke1.Response.Result)
+		}
+		if invoke2.Response.Result != "test.method2" {
+			t.Errorf("expected result2: test.method2, got: %s", invoke2.Response.Result)
+		}
+	})
+
+	t.Run("transform error", func(t *testing.T) {
+		transformErr := errors.New("transform error")
+		client := NewClient(transport, WithResponseTransform(func(resp *JSONRPCResponse) (*JSONRPCResponse, error) {
+			return nil, transformErr
+		}))
+
+		invoke := &Invoke[map[string]string, TestResponse]{Name: "test.method", Request: map[string]string{}}
+		err := client.Invoke(context.Background(), invoke)
+
+		var unmarshalErr *UnmarshalError
+		if !errors.As(err, &unmarshalErr) {
+			t.Fatalf("expected error type: *UnmarshalError, got: %T", err)
+		}
+		if !errors.Is(err, transformErr) {
+			t.Errorf("expected wrapped transform error, got: %v", err)
+		}
+	})
+}
+
+// TestWithRequestTransform tests the WithRequestTransform option
+func TestWithRequestTransform(t *testing.T) {
+	var sent []*JSONRPCRequest
+	transport := &MockTransport{
+		SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			sent = input.Requests
+			output := &SendRequestOutput{}
+			for _, request := range input.Requests {
+				output.Responses = append(output.Responses, &JSONRPCResponse{
+					ID:     request.ID,
+					Result: json.RawMessage(`"ok"`),
+				})
+			}
+			return output, nil
+		},
+	}
+
+	// addTrace injects a trace field into map params and prefixes the method name
+	addTrace := func(request *JSONRPCRequest) error {
+		if request.ID == nil {
+			t.Errorf("expected ID to be assigned before transform")
+		}
+		if params, ok := request.Params.(map[string]string); ok {
+			params["trace"] = request.ID.String()
+		}
+		request.Method = "v2." + request.Method
+		return nil
+	}
+
+	t.Run("single request", func(t *testing.T) {
+		client := NewClient(transport, WithRequestTransform(addTrace))
+
+		invoke := &Invoke[map[string]string, string]{Name: "test.method", Request: map[string]string{}}
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+
+		if sent[0].Method != "v2.test.method" {
+			t.Errorf("expected method: v2.test.method, got: %s", sent[0].Method)
+		}
+		if params := sent[0].Params.(map[string]string); params["trace"] != "1" {
+			t.Errorf("expected trace: 1, got: %s", params["trace"])
+		}
+	})
+
+	t.Run("batch request", func(t *testing.T) {
+		client := NewClient(transport, WithRequestTransform(addTrace))
+
+		invoke1 := &Invoke[map[string]string, string]{Name: "test.method1", Request: map[string]string{}}
+		invoke2 := &Invoke[map[string]string, string]{Name: "test.method2", Request: map[string]string{}}
+		if err := client.InvokeBatch(context.Background(), []MethodCaller{invoke1, invoke2}); err != nil {
+			t.Fatalf("InvokeBatch error: %v", err)
+		}
+
+		for i, want := range []string{"v2.test.method1", "v2.test.method2"} {
+			if sent[i].Method != want {
+				t.Errorf("expected method: %s, got: %s", want, sent[i].Method)
+			}
+		}
+	})
+
+	t.Run("transform error", func(t *testing.T) {
+		transformErr := errors.New("transform error")
+		client := NewClient(transport, WithRequestTransform(func(request *JSONRPCRequest) error {
+			return transformErr
+		}))
+
+		invoke := &Invoke[map[string]string, string]{Name: "test.method", Request: map[string]string{}}
+		err := client.Invoke(context.Background(), invoke)
+
+		var marshalErr *MarshalError
+		if !errors.As(err, &marshalErr) {
+			t.Fatalf("expected error type: *MarshalError, got: %T", err)
+		}
+		if marshalErr.Method != "test.method" {
+			t.Errorf("expected method: test.method, got: %s", marshalErr.Method)
+		}
+		if !errors.Is(err, transformErr) {
+			t.Errorf("expected wrapped transform error, got: %v", err)
+		}
+	})
+}
+
+// TestWithEmptyParams tests the WithEmptyParams option
+func TestWithEmptyParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []ClientOption
+		expected string
+	}{
+		{
+			name:     "default omits params",
+			expected: `{"jsonrpc":"2.0","id":1,"method":"test.method"}`,
+		},
+		{
+			name:     "ParamsOmit",
+			opts:     []ClientOption{WithEmptyParams(ParamsOmit)},
+			expected: `{"jsonrpc":"2.0","id":1,"method":"test.method"}`,
+		},
+		{
+			name:     "ParamsEmptyObject",
+			opts:     []ClientOption{WithEmptyParams(ParamsEmptyObject)},
+			expected: `{"jsonrpc":"2.0","id":1,"method":"test.method","params":{}}`,
+		},
+		{
+			name:     "ParamsEmptyArray",
+			opts:     []ClientOption{WithEmptyParams(ParamsEmptyArray)},
+			expected: `{"jsonrpc":"2.0","id":1,"method":"test.method","params":[]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body []byte
+			transport := &MockTransport{
+				SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+					var err error
+					body, err = json.Marshal(input.Requests[0])
+					if err != nil {
+						t.Fatalf("marshal error: %v", err)
+					}
+					return &SendRequestOutput{
+						Responses: []*JSONRPCResponse{{ID: input.Requests[0].ID, Result: json.RawMessage(`"ok"`)}},
+					}, nil
+				},
+			}
+			client := NewClient(transport, tt.opts...)
+
+			invoke := &Invoke[Omit, string]{Name: "test.method"}
+			if err := client.Invoke(context.Background(), invoke); err != nil {
+				t.Fatalf("Invoke error: %v", err)
+			}
+			if string(body) != tt.expected {
+				t.Errorf("expected JSON: %s, got: %s", tt.expected, body)
+			}
+		})
+	}
+}
+
+// TestWithTimeBasedIDGenerator tests the WithTimeBasedIDGenerator function
+func TestWithTimeBasedIDGenerator(t *testing.T) {
+	t.Run("monotonic IDs", func(t *testing.T) {
+		before := time.Now().UnixMilli() * 1000
+		client := NewClient(&MockTransport{}, WithTimeBasedIDGenerator())
+
+		prev := 0
+		for i := 0; i < 100; i++ {
+			id := client.generateId()
+			if id.intVar == nil {
+				t.Fatalf("expected integer ID, got: %v", id)
+			}
+			if *id.intVar <= prev {
+				t.Fatalf("expected ID greater than %d, got: %d", prev, *id.intVar)
+			}
+			prev = *id.intVar
+		}
+		if int64(prev) < before {
+			t.Errorf("expected IDs derived from the current time, got: %d", prev)
+		}
+	})
+
+	t.Run("thread safety", func(t *testing.T) {
+		client := NewClient(&MockTransport{}, WithTimeBasedIDGenerator())
+
+		var wg sync.WaitGroup
+		idChan := make(chan int, 1000)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					idChan <- *client.generateId().intVar
+				}
+			}()
+		}
+		wg.Wait()
+		close(idChan)
+
+		ids := make(map[int]bool)
+		for id := range idChan {
+			if ids[id] {
+				t.Errorf("duplicate ID generated: %d", id)
+			}
+			ids[id] = true
+		}
+		if len(ids) != 1000 {
+			t.Errorf("expected 1000 unique IDs, got: %d", len(ids))
+		}
+	})
+
+	t.Run("IDs are exact as doubles", func(t *testing.T) {
+		now := time.Date(2200, 1, 1, 0, 0, 0, 0, time.UTC)
+		client := NewClient(&MockTransport{}, WithTimeBasedIDGenerator(), WithClock(testutil.NewFakeClock(now)))
+
+		first := client.generateId().Value().(int)
+		second := client.generateId().Value().(int)
+		if int64(first) != now.UnixMilli()*1000 || second != first+1 {
+			t.Errorf("expected IDs: %d and %d, got: %d and %d", now.UnixMilli()*1000, now.UnixMilli()*1000+1, first, second)
+		}
+		if first >= 1<<53 || int(float64(second)) != second {
+			t.Errorf("expected IDs below 2^53, got: %d", second)
+		}
+	})
+}
+
+// omitWrapper embeds Omit and therefore inherits OmitParams
+type omitWrapper struct {
+	Omit
+}
+
+// conditionalParams omits params when empty
+type conditionalParams struct {
+	Value string `json:"value,omitempty"`
+}
+
+func (p conditionalParams) OmitParams() bool {
+	return p.Value == ""
+}
+
+// TestOmitDetection tests Omit detection through the ParamsOmitter interface
+func TestOmitDetection(t *testing.T) {
+	t.Run("embedded Omit", func(t *testing.T) {
+		invoke := &Invoke[omitWrapper, string]{Name: "test.method"}
+
+		request := invoke.JSONRPCRequest()
+		if request.Params != nil {
+			t.Errorf("expected params to be nil for embedded Omit, got: %v", request.Params)
+		}
+		if err := invoke.Unmarshal(&JSONRPCResponse{ID: NewID(1)}); err != nil {
+			t.Errorf("expected no error for embedded Omit, got: %v", err)
+		}
+	})
+
+	t.Run("custom OmitParams", func(t *testing.T) {
+		empty := &Invoke[conditionalParams, string]{Name: "test.method"}
+		if request := empty.JSONRPCRequest(); request.Params != nil {
+			t.Errorf("expected params to be nil, got: %v", request.Params)
+		}
+
+		filled := &Invoke[conditionalParams, string]{Name: "test.method", Request: conditionalParams{Value: "x"}}
+		if request := filled.JSONRPCRequest(); request.Params == nil {
+			t.Error("expected params to be non-nil")
+		}
+	})
+}
+
+// TestWithVersion tests the WithVersion option
+func TestWithVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []ClientOption
+		expected string
+	}{
+		{
+			name:     "default",
+			expected: `{"jsonrpc":"2.0","id":1,"method":"test.method"}`,
+		},
+		{
+			name:     "2.0",
+			opts:     []ClientOption{WithVersion("2.0")},
+			expected: `{"jsonrpc":"2.0","id":1,"method":"test.method"}`,
+		},
+		{
+			name:     "1.0",
+			opts:     []ClientOption{WithVersion("1.0")},
+			expected: `{"jsonrpc":"1.0","id":1,"method":"test.method"}`,
+		},
+		{
+			name:     "empty omits the member",
+			opts:     []ClientOption{WithVersion("")},
+			expected: `{"id":1,"method":"test.method"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body []byte
+			transport := &MockTransport{
+				SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+					var err error
+					body, err = json.Marshal(input.Requests[0])
+					if err != nil {
+						t.Fatalf("marshal error: %v", err)
+					}
+					return &SendRequestOutput{
+						Responses: []*JSONRPCResponse{{ID: input.Requests[0].ID, Result: json.RawMessage(`"ok"`)}},
+					}, nil
+				},
+			}
+			client := NewClient(transport, tt.opts...)
+
+			invoke := &Invoke[Omit, string]{Name: "test.method"}
+			if err := client.Invoke(context.Background(), invoke); err != nil {
+				t.Fatalf("Invoke error: %v", err)
+			}
+			if string(body) != tt.expected {
+				t.Errorf("expected JSON: %s, got: %s", tt.expected, body)
+			}
+		})
+	}
+}
+
+// TestWithClock tests the WithClock option
+func TestWithClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := testutil.NewFakeClock(start)
+	client := NewClient(&MockTransport{}, WithClock(clock), WithTimeBasedIDGenerator())
+
+	id1 := client.generateId()
+	if *id1.intVar != int(start.UnixMilli()*1000) {
+		t.Errorf("expected ID: %d, got: %d", start.UnixMilli()*1000, *id1.intVar)
+	}
+
+	// The clock has not moved, so the next ID is bumped by one
+	id2 := client.generateId()
+	if *id2.intVar != int(start.UnixMilli()*1000)+1 {
+		t.Errorf("expected ID: %d, got: %d", start.UnixMilli()*1000+1, *id2.intVar)
+	}
+
+	clock.Advance(time.Second)
+	id3 := client.generateId()
+	if want := int(start.Add(time.Second).UnixMilli() * 1000); *id3.intVar != want {
+		t.Errorf("expected ID: %d, got: %d", want, *id3.intVar)
+	}
+}
+
+// TestInvokeIDStates tests how Invoke treats nil, notification and explicitly null IDs
+func TestInvokeIDStates(t *testing.T) {
+	type TestResponse struct {
+		Result string `json:"result"`
+	}
+
+	tests := []struct {
+		name         string
+		id           *IDValue
+		expectedJSON string
+		notification bool
+	}{
+		{
+			name:         "nil ID generates one",
+			id:           nil,
+			expectedJSON: `{"jsonrpc":"2.0","id":1,"method":"test.method","params":{}}`,
+		},
+		{
+			name:         "notification ID omits the member",
+			id:           NewNotificationID(),
+			expectedJSON: `{"jsonrpc":"2.0","method":"test.method","params":{}}`,
+			notification: true,
+		},
+		{
+			name:         "explicit null ID is sent and expects a response",
+			id:           NewNullID(),
+			expectedJSON: `{"jsonrpc":"2.0","id":null,"method":"test.method","params":{}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body []byte
+			transport := &MockTransport{
+				SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+					var err error
+					body, err = json.Marshal(input.Requests[0])
+					if err != nil {
+						t.Fatalf("marshal error: %v", err)
+					}
+					if tt.notification {
+						return &SendRequestOutput{}, nil
+					}
+					respBody := `{"jsonrpc":"2.0","id":` + input.Requests[0].ID.String() + `,"result":{"result":"success"}}`
+					var response JSONRPCResponse
+					if err := json.Unmarshal([]byte(respBody), &response); err != nil {
+						t.Fatalf("unmarshal error: %v", err)
+					}
+					return &SendRequestOutput{Responses: []*JSONRPCResponse{&response}}, nil
+				},
+			}
+			client := NewClient(transport)
+
+			invoke := &Invoke[map[string]string, TestResponse]{
+				ID:      tt.id,
+				Name:    "test.method",
+				Request: map[string]string{},
+			}
+			if err := client.Invoke(context.Background(), invoke); err != nil {
+				t.Fatalf("Invoke error: %v", err)
+			}
+			if string(body) != tt.expectedJSON {
+				t.Errorf("expected JSON: %s, got: %s", tt.expectedJSON, body)
+			}
+
+			expectedResult := "success"
+			if tt.notification {
+				expectedResult = ""
+			}
+			if invoke.Response.Result != expectedResult {
+				t.Errorf("expected result: %q, got: %q", expectedResult, invoke.Response.Result)
+			}
+		})
+	}
+
+	t.Run("explicit null ID in batch", func(t *testing.T) {
+		transport := &MockTransport{
+			SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+				var responses []*JSONRPCResponse
+				if err := json.Unmarshal([]byte(`[{"jsonrpc":"2.0","id":1,"result":{"result":"one"}},{"jsonrpc":"2.0","id":null,"result":{"result":"null"}}]`), &responses); err != nil {
+					t.Fatalf("unmarshal error: %v", err)
+				}
+				return &SendRequestOutput{Responses: responses}, nil
+			},
+		}
+		client := NewClient(transport)
+
+		invoke1 := &Invoke[map[string]string, TestResponse]{Name: "test.method1", Request: map[string]string{}}
+		invoke2 := &Invoke[map[string]string, TestResponse]{ID: NewNullID(), Name: "test.method2", Request: map[string]string{}}
+		if err := client.InvokeBatch(context.Background(), []MethodCaller{invoke1, invoke2}); err != nil {
+			t.Fatalf("InvokeBatch error: %v", err)
+		}
+		if invoke1.Response.Result != "one" {
+			t.Errorf("expected result1: one, got: %s", invoke1.Response.Result)
+		}
+		if invoke2.Response.Result != "null" {
+			t.Errorf("expected result2: null, got: %s", invoke2.Response.Result)
+		}
+	})
+}
+
+// TestWithValidation tests the WithValidation option
+func TestWithValidation(t *testing.T) {
+	transport := &MockTransport{
+		SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			output := &SendRequestOutput{}
+			for _, request := range input.Requests {
+				output.Responses = append(output.Responses, &JSONRPCResponse{ID: request.ID, Result: json.RawMessage(`"ok"`)})
+			}
+			return output, nil
+		},
+	}
+
+	t.Run("valid requests", func(t *testing.T) {
+		client := NewClient(transport, WithValidation())
+
+		invoke1 := &Invoke[Omit, string]{Name: "test.method1"}
+		invoke2 := &Invoke[Omit, string]{Name: "test.method2"}
+		notify1 := AsNotification(&Invoke[Omit, Omit]{Name: "test.notify"})
+		notify2 := AsNotification(&Invoke[Omit, Omit]{Name: "test.notify"})
+		if err := client.InvokeBatch(context.Background(), []MethodCaller{invoke1, invoke2, notify1, notify2}); err != nil {
+			t.Fatalf("InvokeBatch error: %v", err)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		reqs    []MethodCaller
+		message string
+	}{
+		{
+			name:    "empty method",
+			reqs:    []MethodCaller{&Invoke[Omit, string]{}},
+			message: "empty method name",
+		},
+		{
+			name:    "reserved prefix",
+			reqs:    []MethodCaller{&Invoke[Omit, string]{Name: "rpc.discover"}},
+			message: "reserved",
+		},
+		{
+			name: "duplicate batch IDs",
+			reqs: []MethodCaller{
+				&Invoke[Omit, string]{ID: NewID(1), Name: "test.method1"},
+				&Invoke[Omit, string]{ID: NewID(1), Name: "test.method2"},
+			},
+			message: "same ID 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(transport, WithValidation())
+
+			var err error
+			if len(tt.reqs) == 1 {
+				err = client.Invoke(context.Background(), tt.reqs[0])
+			} else {
+				err = client.InvokeBatch(context.Background(), tt.reqs)
+			}
+
+			var invalidErr *InvalidRequestError
+			if !errors.As(err, &invalidErr) {
+				t.Fatalf("expected error type: *InvalidRequestError, got: %T", err)
+			}
+			if !strings.Contains(invalidErr.Message, tt.message) {
+				t.Errorf("expected message containing %q, got: %s", tt.message, invalidErr.Message)
+			}
+
+			// Without validation the same requests are sent as-is
+			client = NewClient(transport)
+			if len(tt.reqs) == 1 {
+				err = client.Invoke(context.Background(), tt.reqs[0])
+			} else {
+				err = client.InvokeBatch(context.Background(), tt.reqs)
+			}
+			if errors.As(err, &invalidErr) {
+				t.Errorf("expected no InvalidRequestError without validation, got: %v", err)
+			}
+		})
+	}
+}
+
+// TestInvokeBatchWithNotificationsOverHTTP tests that notifications in a batch are
+// serialized without an id member and that only call responses are routed
+func TestInvokeBatchWithNotificationsOverHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("failed to read body: %v", err)
+		}
+
+		expected := `[` +
+			`{"jsonrpc":"2.0","id":1,"method":"add","params":[1,2]},` +
+			`{"jsonrpc":"2.0","method":"log","params":["hello"]},` +
+			`{"jsonrpc":"2.0","id":2,"method":"add","params":[3,4]}` +
+			`]`
+		if string(body) != expected {
+			t.Errorf("expected body: %s, got: %s", expected, body)
+		}
+
+		// Respond out of order and only to the calls
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[{"jsonrpc":"2.0","id":2,"result":7},{"jsonrpc":"2.0","id":1,"result":3}]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(NewHTTPTransport(server.URL))
+
+	add1 := &Invoke[[]int, int]{Name: "add", Request: []int{1, 2}}
+	notify := AsNotification(&Invoke[[]string, int]{Name: "log", Request: []string{"hello"}})
+	add2 := &Invoke[[]int, int]{Name: "add", Request: []int{3, 4}}
+
+	if err := client.InvokeBatch(context.Background(), []MethodCaller{add1, notify, add2}); err != nil {
+		t.Fatalf("InvokeBatch error: %v", err)
+	}
+	if add1.Response != 3 {
+		t.Errorf("expected result1: 3, got: %d", add1.Response)
+	}
+	if add2.Response != 7 {
+		t.Errorf("expected result2: 7, got: %d", add2.Response)
+	}
+	if notify.Response != 0 {
+		t.Errorf("expected notification response to be untouched, got: %d", notify.Response)
+	}
+}
+
+// TestInvokeBatchWithCorruptElement tests that one malformed batch element only fails its own invoke
+func TestInvokeBatchWithCorruptElement(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[{"jsonrpc":"2.0","id":1,"result":1},{"jsonrpc":"2.0","id":2,"error":"boom"},{"jsonrpc":"2.0","id":3,"result":3}]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(NewHTTPTransport(server.URL))
+
+	invoke1 := &Invoke[[]int, int]{Name: "test.method1", Request: []int{}}
+	invoke2 := &Invoke[[]int, int]{Name: "test.method2", Request: []int{}}
+	invoke3 := &Invoke[[]int, int]{Name: "test.method3", Request: []int{}}
+
+	err := client.InvokeBatch(context.Background(), []MethodCaller{invoke1, invoke2, invoke3})
+
+	var unmarshalErr *UnmarshalError
+	if !errors.As(err, &unmarshalErr) {
+		t.Fatalf("expected error type: *UnmarshalError, got: %T", err)
+	}
+	if unmarshalErr.Method != "test.method2" {
+		t.Errorf("expected method: test.method2, got: %s", unmarshalErr.Method)
+	}
+	if invoke1.Response != 1 {
+		t.Errorf("expected result1: 1, got: %d", invoke1.Response)
+	}
+	if invoke3.Response != 3 {
+		t.Errorf("expected result3: 3, got: %d", invoke3.Response)
+	}
+}
+
+// TestErrorsIncludeRequestID tests that the client stamps request IDs onto returned errors
+func TestErrorsIncludeRequestID(t *testing.T) {
+	t.Run("Invoke RPC error", func(t *testing.T) {
+		transport := &MockTransport{
+			SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+				return &SendRequestOutput{Responses: []*JSONRPCResponse{{
+					ID:    input.Requests[0].ID,
+					Error: &JSONRPCError{Code: -32601, Message: "Method not found"},
+				}}}, nil
+			},
+		}
+		client := NewClient(transport, WithIDGenerator(func() *IDValue { return NewID("req-1") }))
+
+		err := client.Invoke(context.Background(), &Invoke[Omit, string]{Name: "test.method"})
+		if err == nil || !strings.Contains(err.Error(), "id=req-1") {
+			t.Errorf("expected request ID in error message, got: %v", err)
+		}
+	})
+
+	t.Run("Invoke transport error", func(t *testing.T) {
+		transport := &MockTransport{
+			SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+				return nil, &StatusCodeError{Method: input.Requests[0].Method, StatusCode: 503}
+			},
+		}
+		client := NewClient(transport)
+
+		err := client.Invoke(context.Background(), &Invoke[Omit, string]{Name: "test.method"})
+		var statusErr *StatusCodeError
+		if !errors.As(err, &statusErr) {
+			t.Fatalf("expected error type: *StatusCodeError, got: %T", err)
+		}
+		if !statusErr.ID.Equal(NewID(1)) {
+			t.Errorf("expected ID: 1, got: %v", statusErr.ID)
+		}
+		if !strings.Contains(err.Error(), "id=1") {
+			t.Errorf("expected request ID in error message, got: %v", err)
+		}
+	})
+
+	t.Run("InvokeBatch per-invoke errors", func(t *testing.T) {
+		transport := &MockTransport{
+			SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+				return &SendRequestOutput{Responses: []*JSONRPCResponse{
+					{ID: input.Requests[0].ID, Result: json.RawMessage(`"ok"`)},
+					{ID: input.Requests[1].ID, Error: &JSONRPCError{Code: -32000, Message: "busy"}},
+				}}, nil
+			},
+		}
+		client := NewClient(transport)
+
+		err := client.InvokeBatch(context.Background(), []MethodCaller{
+			&Invoke[Omit, string]{Name: "test.method1"},
+			&Invoke[Omit, string]{Name: "test.method2"},
+			&Invoke[Omit, string]{Name: "test.method3"},
+		})
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "[test.method2 id=2]") {
+			t.Errorf("expected ID of the failed call in error message, got: %v", err)
+		}
+		if !strings.Contains(err.Error(), "[test.method3 id=3]") {
+			t.Errorf("expected ID of the missing call in error message, got: %v", err)
+		}
+	})
+}
+
+// TestInvokeBatchEmptyResponses tests that a batch without responses reports EmptyResponseError
+func TestInvokeBatchEmptyResponses(t *testing.T) {
+	for _, responses := range [][]*JSONRPCResponse{nil, {}} {
+		transport := &MockTransport{
+			SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+				return &SendRequestOutput{Responses: responses}, nil
+			},
+		}
+		client := NewClient(transport)
+
+		t.Run("with calls", func(t *testing.T) {
+			err := client.InvokeBatch(context.Background(), []MethodCaller{
+				AsNotification(&Invoke[Omit, Omit]{Name: "test.notify"}),
+				&Invoke[Omit, string]{Name: "test.method1"},
+				&Invoke[Omit, string]{Name: "test.method2"},
+			})
+
+			var emptyErr *EmptyResponseError
+			if !errors.As(err, &emptyErr) {
+				t.Fatalf("expected error type: *EmptyResponseError, got: %T", err)
+			}
+			if emptyErr.Method != "test.method1" {
+				t.Errorf("expected method: test.method1, got: %s", emptyErr.Method)
+			}
+		})
+
+		t.Run("notifications only", func(t *testing.T) {
+			err := client.InvokeBatch(context.Background(), []MethodCaller{
+				AsNotification(&Invoke[Omit, Omit]{Name: "test.notify1"}),
+				AsNotification(&Invoke[Omit, Omit]{Name: "test.notify2"}),
+			})
+			if err != nil {
+				t.Errorf("expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+// TestCall tests the Call method
+func TestCall(t *testing.T) {
+	t.Run("returns raw result", func(t *testing.T) {
+		transport := &MockTransport{
+			SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+				request := input.Requests[0]
+				if request.Method != "add" {
+					t.Errorf("expected method: add, got: %s", request.Method)
+				}
+				return &SendRequestOutput{Responses: []*JSONRPCResponse{{
+					Version: "2.0",
+					ID:      request.ID,
+					Result:  json.RawMessage(`{"sum":3}`),
+				}}}, nil
+			},
+		}
+		client := NewClient(transport)
+
+		resp, err := client.Call(context.Background(), "add", []int{1, 2})
+		if err != nil {
+			t.Fatalf("Call error: %v", err)
+		}
+		if !resp.ID.Equal(NewID(1)) {
+			t.Errorf("expected ID: 1, got: %v", resp.ID)
+		}
+		if resp.Version != "2.0" {
+			t.Errorf("expected version: 2.0, got: %s", resp.Version)
+		}
+		if string(resp.Result) != `{"sum":3}` {
+			t.Errorf("expected result: {\"sum\":3}, got: %s", resp.Result)
+		}
+	})
+
+	t.Run("returns error response without converting", func(t *testing.T) {
+		transport := &MockTransport{
+			SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+				return &SendRequestOutput{Responses: []*JSONRPCResponse{{
+					ID:    input.Requests[0].ID,
+					Error: &JSONRPCError{Code: -32601, Message: "Method not found"},
+				}}}, nil
+			},
+		}
+		client := NewClient(transport)
+
+		resp, err := client.Call(context.Background(), "missing", nil)
+		if err != nil {
+			t.Fatalf("Call error: %v", err)
+		}
+		if resp.Error == nil || resp.Error.Code != -32601 {
+			t.Errorf("expected error code: -32601, got: %v", resp.Error)
+		}
+	})
+
+	t.Run("empty response", func(t *testing.T) {
+		client := NewClient(&MockTransport{})
+
+		_, err := client.Call(context.Background(), "add", nil)
+		var emptyErr *EmptyResponseError
+		if !errors.As(err, &emptyErr) {
+			t.Fatalf("expected error type: *EmptyResponseError, got: %T", err)
+		}
+	})
+}
+
+func TestWithUseNumber(t *testing.T) {
+	const large = "9007199254740993" // 2^53 + 1, not representable as float64
+
+	transport := &MockTransport{
+		SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			responses := make([]*JSONRPCResponse, len(input.Requests))
+			for i, req := range input.Requests {
+				responses[i] = &JSONRPCResponse{
+					Version: "2.0",
+					ID:      req.ID,
+					Result:  json.RawMessage(`{"balance":` + large + `,"amount":` + large + `}`),
+				}
+			}
+			return &SendRequestOutput{Responses: responses}, nil
+		},
+	}
+
+	type balance struct {
+		Balance any   `json:"balance"`
+		Amount  int64 `json:"amount"`
+	}
+
+	t.Run("preserves precision in interface values", func(t *testing.T) {
+		client := NewClient(transport, WithUseNumber())
+
+		raw := FromRequest[map[string]any](&JSONRPCRequest{Method: "getBalance"})
+		if err := client.Invoke(context.Background(), raw); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if got, ok := raw.Response["balance"].(json.Number); !ok || got.String() != large {
+			t.Errorf("expected balance: json.Number(%s), got: %#v", large, raw.Response["balance"])
+		}
+	})
+
+	t.Run("interface fields of struct results", func(t *testing.T) {
+		client := NewClient(transport, WithUseNumber())
+
+		invoke := &Invoke[[]string, balance]{Name: "getBalance", Request: []string{"acct"}}
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if got, ok := invoke.Response.Balance.(json.Number); !ok || got.String() != large {
+			t.Errorf("expected balance: json.Number(%s), got: %#v", large, invoke.Response.Balance)
+		}
+		if invoke.Response.Amount != 9007199254740993 {
+			t.Errorf("expected amount: %s, got: %d", large, invoke.Response.Amount)
+		}
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		client := NewClient(transport, WithUseNumber())
+
+		first := &Invoke[[]string, map[string]any]{Name: "getBalance", Request: []string{"a"}}
+		second := &Invoke[[]string, map[string]any]{Name: "getBalance", Request: []string{"b"}}
+		if err := client.InvokeBatch(context.Background(), []MethodCaller{first, second}); err != nil {
+			t.Fatalf("InvokeBatch error: %v", err)
+		}
+		for _, invoke := range []*Invoke[[]string, map[string]any]{first, second} {
+			if _, ok := invoke.Response["balance"].(json.Number); !ok {
+				t.Errorf("expected balance type: json.Number, got: %T", invoke.Response["balance"])
+			}
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		client := NewClient(transport)
+
+		invoke := &Invoke[[]string, map[string]any]{Name: "getBalance", Request: []string{"acct"}}
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if _, ok := invoke.Response["balance"].(float64); !ok {
+			t.Errorf("expected balance type: float64, got: %T", invoke.Response["balance"])
+		}
+	})
+}
+
+// TestResponseProcessingConsistency checks that Invoke and InvokeBatch handle each kind of
+// response the same way
+func TestResponseProcessingConsistency(t *testing.T) {
+	tests := []struct {
+		name      string
+		response  *JSONRPCResponse
+		transform ResponseTransform
+		check     func(t *testing.T, err error)
+		expected  string
+	}{
+		{
+			name:     "success",
+			response: &JSONRPCResponse{Version: "2.0", Result: json.RawMessage(`"ok"`)},
+			check: func(t *testing.T, err error) {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+			},
+			expected: "ok",
+		},
+		{
+			name:     "rpc error",
+			response: &JSONRPCResponse{Version: "2.0", Error: &JSONRPCError{Code: -32000, Message: "failed"}},
+			check: func(t *testing.T, err error) {
+				var rpcErr *RPCError
+				if !errors.As(err, &rpcErr) || rpcErr.Code != -32000 {
+					t.Errorf("expected RPCError with code -32000, got: %v", err)
+				}
+			},
+		},
+		{
+			name:     "missing result",
+			response: &JSONRPCResponse{Version: "2.0"},
+			check: func(t *testing.T, err error) {
+				var emptyErr *EmptyResultError
+				if !errors.As(err, &emptyErr) {
+					t.Errorf("expected error type: *EmptyResultError, got: %T", err)
+				}
+			},
+		},
+		{
+			name:     "undecodable result",
+			response: &JSONRPCResponse{Version: "2.0", Result: json.RawMessage(`123`)},
+			check: func(t *testing.T, err error) {
+				var unmarshalErr *UnmarshalError
+				if !errors.As(err, &unmarshalErr) {
+					t.Errorf("expected error type: *UnmarshalError, got: %T", err)
+				}
+			},
+		},
+		{
+			name:     "transform error",
+			response: &JSONRPCResponse{Version: "2.0", Result: json.RawMessage(`"ok"`)},
+			transform: func(resp *JSONRPCResponse) (*JSONRPCResponse, error) {
+				return nil, errors.New("transform failed")
+			},
+			check: func(t *testing.T, err error) {
+				var unmarshalErr *UnmarshalError
+				if !errors.As(err, &unmarshalErr) {
+					t.Errorf("expected error type: *UnmarshalError, got: %T", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		transport := &MockTransport{
+			SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+				resp := *tt.response
+				resp.ID = input.Requests[0].ID
+				return &SendRequestOutput{Responses: []*JSONRPCResponse{&resp}}, nil
+			},
+		}
+		var opts []ClientOption
+		if tt.transform != nil {
+			opts = append(opts, WithResponseTransform(tt.transform))
+		}
+		client := NewClient(transport, opts...)
+
+		t.Run(tt.name+"/invoke", func(t *testing.T) {
+			invoke := &Invoke[[]int, string]{Name: "test.method", Request: []int{1}}
+			err := client.Invoke(context.Background(), invoke)
+			tt.check(t, err)
+			if invoke.Response != tt.expected {
+				t.Errorf("expected response: %q, got: %q", tt.expected, invoke.Response)
+			}
+		})
+
+		t.Run(tt.name+"/batch", func(t *testing.T) {
+			invoke := &Invoke[[]int, string]{Name: "test.method", Request: []int{1}}
+			err := client.InvokeBatch(context.Background(), []MethodCaller{invoke})
+			tt.check(t, err)
+			if invoke.Response != tt.expected {
+				t.Errorf("expected response: %q, got: %q", tt.expected, invoke.Response)
+			}
+		})
+	}
+}
+
+type validatedBalance struct {
+	Balance int `json:"balance"`
+}
+
+var errNegativeBalance = errors.New("negative balance")
+
+func (b validatedBalance) Validate() error {
+	if b.Balance < 0 {
+		return errNegativeBalance
+	}
+	return nil
+}
+
+type validatedName struct {
+	Name string `json:"name"`
+}
+
+func (n *validatedName) Validate() error {
+	if n.Name == "" {
+		return errors.New("empty name")
+	}
+	return nil
+}
+
+func TestResultValidation(t *testing.T) {
+	newClient := func(result string) *Client {
+		return NewClient(&MockTransport{
+			SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+				responses := make([]*JSONRPCResponse, len(input.Requests))
+				for i, req := range input.Requests {
+					responses[i] = &JSONRPCResponse{Version: "2.0", ID: req.ID, Result: json.RawMessage(result)}
+				}
+				return &SendRequestOutput{Responses: responses}, nil
+			},
+		})
+	}
+
+	t.Run("valid result", func(t *testing.T) {
+		invoke := &Invoke[[]string, validatedBalance]{Name: "getBalance", Request: []string{"acct"}}
+		if err := newClient(`{"balance":10}`).Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if invoke.Response.Balance != 10 {
+			t.Errorf("expected balance: 10, got: %d", invoke.Response.Balance)
+		}
+	})
+
+	t.Run("value receiver", func(t *testing.T) {
+		invoke := &Invoke[[]string, validatedBalance]{Name: "getBalance", Request: []string{"acct"}}
+		err := newClient(`{"balance":-1}`).Invoke(context.Background(), invoke)
+
+		var validationErr *ResultValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected error type: *ResultValidationError, got: %T", err)
+		}
+		if validationErr.Method != "getBalance" {
+			t.Errorf("expected method: getBalance, got: %s", validationErr.Method)
+		}
+		if validationErr.ID == nil || validationErr.ID.String() != "1" {
+			t.Errorf("expected ID: 1, got: %v", validationErr.ID)
+		}
+		if !errors.Is(err, errNegativeBalance) {
+			t.Errorf("expected wrapped validation error, got: %v", err)
+		}
+	})
+
+	t.Run("pointer receiver", func(t *testing.T) {
+		invoke := &Invoke[[]string, validatedName]{Name: "getUser", Request: []string{"id"}}
+		err := newClient(`{"name":""}`).Invoke(context.Background(), invoke)
+
+		var validationErr *ResultValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected error type: *ResultValidationError, got: %T", err)
+		}
+	})
+
+	t.Run("pointer result type", func(t *testing.T) {
+		invoke := &Invoke[[]string, *validatedName]{Name: "getUser", Request: []string{"id"}}
+		err := newClient(`{"name":""}`).Invoke(context.Background(), invoke)
+
+		var validationErr *ResultValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected error type: *ResultValidationError, got: %T", err)
+		}
+	})
+
+	t.Run("null pointer result", func(t *testing.T) {
+		invoke := &Invoke[[]string, *validatedName]{Name: "getUser", Request: []string{"id"}}
+		if err := newClient(`null`).Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if invoke.Response != nil {
+			t.Errorf("expected nil response, got: %v", invoke.Response)
+		}
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		invoke := &Invoke[[]string, validatedBalance]{Name: "getBalance", Request: []string{"acct"}}
+		err := newClient(`{"balance":-1}`).InvokeBatch(context.Background(), []MethodCaller{invoke})
+
+		var validationErr *ResultValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected error type: *ResultValidationError, got: %T", err)
+		}
+	})
+
+	t.Run("raw invoke", func(t *testing.T) {
+		raw := FromRequest[validatedBalance](&JSONRPCRequest{Method: "getBalance"})
+		err := newClient(`{"balance":-1}`).Invoke(context.Background(), raw)
+
+		var validationErr *ResultValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected error type: *ResultValidationError, got: %T", err)
+		}
+	})
+}
+
+func TestInvokeBatchNotificationsOnly(t *testing.T) {
+	newBatch := func() []*Invoke[[]string, string] {
+		return []*Invoke[[]string, string]{
+			AsNotification(&Invoke[[]string, string]{Name: "log", Request: []string{"a"}}),
+			AsNotification(&Invoke[[]string, string]{Name: "log", Request: []string{"b"}}),
+			AsNotification(&Invoke[[]string, string]{Name: "log", Request: []string{"c"}}),
+		}
+	}
+	callers := func(batch []*Invoke[[]string, string]) []MethodCaller {
+		reqs := make([]MethodCaller, len(batch))
+		for i, invoke := range batch {
+			reqs[i] = invoke
+		}
+		return reqs
+	}
+
+	bodies := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{name: "empty body", status: http.StatusOK, body: ""},
+		{name: "no content", status: http.StatusNoContent, body: ""},
+		{name: "empty array", status: http.StatusOK, body: "[]"},
+		{name: "single error object", status: http.StatusOK, body: `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Invalid Request"}}`},
+	}
+
+	for _, tt := range bodies {
+		t.Run(tt.name, func(t *testing.T) {
+			var received []JSONRPCRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+					t.Errorf("expected a batch array: %v", err)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient(NewHTTPTransport(server.URL))
+			batch := newBatch()
+			if err := client.InvokeBatch(context.Background(), callers(batch)); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if len(received) != 3 {
+				t.Errorf("expected 3 notifications to be sent, got: %d", len(received))
+			}
+			for i, invoke := range batch {
+				if invoke.Response != "" {
+					t.Errorf("expected Response %d to be untouched, got: %q", i, invoke.Response)
+				}
+			}
+		})
+	}
+
+	t.Run("nil output", func(t *testing.T) {
+		client := NewClient(&NilOutputTransport{})
+		if err := client.InvokeBatch(context.Background(), callers(newBatch())); err != nil {
+			t.Errorf("expected no error, got: %v", err)
+		}
+	})
+}
+
+func TestNotify(t *testing.T) {
+	t.Run("sends a notification", func(t *testing.T) {
+		var sent *JSONRPCRequest
+		transport := &MockTransport{
+			SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+				sent = input.Requests[0]
+				return &SendRequestOutput{}, nil
+			},
+		}
+		client := NewClient(transport)
+
+		if err := Notify(context.Background(), client, "log", map[string]string{"level": "info"}); err != nil {
+			t.Fatalf("Notify error: %v", err)
+		}
+		if sent.Method != "log" {
+			t.Errorf("expected method: log, got: %s", sent.Method)
+		}
+		if !sent.ID.IsNotification() {
+			t.Errorf("expected a notification ID, got: %v", sent.ID)
+		}
+		body, _ := json.Marshal(sent)
+		if strings.Contains(string(body), `"id"`) {
+			t.Errorf("expected id to be omitted, got: %s", body)
+		}
+	})
+
+	t.Run("tolerates ack over HTTP", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"jsonrpc":"2.0","result":null}`))
+		}))
+		defer server.Close()
+
+		client := NewClient(NewHTTPTransport(server.URL))
+		if err := Notify(context.Background(), client, "log", []string{"hello"}); err != nil {
+			t.Errorf("expected no error, got: %v", err)
+		}
+	})
+
+	t.Run("transport error", func(t *testing.T) {
+		transport := &MockTransport{
+			SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+				return nil, &InvokeError{Method: input.Requests[0].Method, Err: errors.New("network down")}
+			},
+		}
+		client := NewClient(transport)
+
+		err := Notify(context.Background(), client, "log", Omit{})
+
+		var invokeErr *InvokeError
+		if !errors.As(err, &invokeErr) {
+			t.Fatalf("expected error type: *InvokeError, got: %T", err)
+		}
+	})
+}
+
+func TestWithUnexpectedResponseHandler(t *testing.T) {
+	newServer := func(body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		}))
+	}
+
+	t.Run("reply to a notification", func(t *testing.T) {
+		server := newServer(`{"jsonrpc":"2.0","id":null,"result":"unexpected"}`)
+		defer server.Close()
+
+		var gotRequests []*JSONRPCRequest
+		var gotResponses []*JSONRPCResponse
+		client := NewClient(NewHTTPTransport(server.URL), WithUnexpectedResponseHandler(
+			func(requests []*JSONRPCRequest, responses []*JSONRPCResponse) {
+				gotRequests, gotResponses = requests, responses
+			}))
+
+		if err := Notify(context.Background(), client, "log", []string{"hello"}); err != nil {
+			t.Fatalf("Notify error: %v", err)
+		}
+		if len(gotRequests) != 1 || gotRequests[0].Method != "log" {
+			t.Errorf("expected the log notification, got: %v", gotRequests)
+		}
+		if len(gotResponses) != 1 || string(gotResponses[0].Result) != `"unexpected"` {
+			t.Errorf("expected the unexpected response, got: %v", gotResponses)
+		}
+	})
+
+	t.Run("replies to a notification-only batch", func(t *testing.T) {
+		server := newServer(`[{"jsonrpc":"2.0","id":null,"result":1},{"jsonrpc":"2.0","id":null,"result":2}]`)
+		defer server.Close()
+
+		var gotResponses []*JSONRPCResponse
+		client := NewClient(NewHTTPTransport(server.URL), WithUnexpectedResponseHandler(
+			func(requests []*JSONRPCRequest, responses []*JSONRPCResponse) {
+				gotResponses = responses
+			}))
+
+		batch := []MethodCaller{
+			AsNotification(&Invoke[[]string, Omit]{Name: "log", Request: []string{"a"}}),
+			AsNotification(&Invoke[[]string, Omit]{Name: "log", Request: []string{"b"}}),
+		}
+		if err := client.InvokeBatch(context.Background(), batch); err != nil {
+			t.Fatalf("InvokeBatch error: %v", err)
+		}
+		if len(gotResponses) != 2 {
+			t.Errorf("expected 2 unexpected responses, got: %d", len(gotResponses))
+		}
+	})
+
+	t.Run("not called without a reply", func(t *testing.T) {
+		server := newServer("")
+		defer server.Close()
+
+		called := false
+		client := NewClient(NewHTTPTransport(server.URL), WithUnexpectedResponseHandler(
+			func(requests []*JSONRPCRequest, responses []*JSONRPCResponse) {
+				called = true
+			}))
+
+		if err := Notify(context.Background(), client, "log", []string{"hello"}); err != nil {
+			t.Fatalf("Notify error: %v", err)
+		}
+		if called {
+			t.Error("expected handler not to be called")
+		}
+	})
+}
+
+func TestInvokeBatchWithIDs(t *testing.T) {
+	var sent []*JSONRPCRequest
+	transport := &MockTransport{
+		SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			sent = input.Requests
+			var responses []*JSONRPCResponse
+			for _, req := range input.Requests {
+				if !req.ID.IsNotification() {
+					responses = append(responses, &JSONRPCResponse{Version: "2.0", ID: req.ID, Result: json.RawMessage(`"ok"`)})
+				}
+			}
+			return &SendRequestOutput{Responses: responses}, nil
+		},
+	}
+
+	t.Run("IDs in request order", func(t *testing.T) {
+		client := NewClient(transport, WithSequenceIDGeneratorStart(100))
+
+		reqs := []MethodCaller{
+			&Invoke[[]int, string]{Name: "a", Request: []int{1}},
+			&Invoke[[]int, string]{ID: NewID("custom"), Name: "b", Request: []int{2}},
+			AsNotification(&Invoke[[]int, string]{Name: "c", Request: []int{3}}),
+			&Invoke[[]int, string]{Name: "d", Request: []int{4}},
+		}
+		ids, err := client.InvokeBatchWithIDs(context.Background(), reqs)
+		if err != nil {
+			t.Fatalf("InvokeBatchWithIDs error: %v", err)
+		}
+		if len(ids) != 4 {
+			t.Fatalf("expected 4 IDs, got: %d", len(ids))
+		}
+		if !ids[0].Equal(NewID(100)) || !ids[1].Equal(NewID("custom")) || !ids[3].Equal(NewID(101)) {
+			t.Errorf("expected IDs: 100, custom, 101, got: %v, %v, %v", ids[0], ids[1], ids[3])
+		}
+		if !ids[2].IsNotification() {
+			t.Errorf("expected a notification ID, got: %v", ids[2])
+		}
+		for i, request := range sent {
+			if request.ID != ids[i] {
+				t.Errorf("expected ID %d to be the one sent, got: %v and %v", i, ids[i], request.ID)
+			}
+		}
+	})
+
+	t.Run("collapsed duplicates share the sent ID", func(t *testing.T) {
+		client := NewClient(transport, WithDedupeBatch(DedupeCollapse))
+
+		reqs := []MethodCaller{
+			&Invoke[[]int, string]{Name: "a", Request: []int{1}},
+			&Invoke[[]int, string]{Name: "a", Request: []int{1}},
+		}
+		ids, err := client.InvokeBatchWithIDs(context.Background(), reqs)
+		if err != nil {
+			t.Fatalf("InvokeBatchWithIDs error: %v", err)
+		}
+		if len(sent) != 1 || ids[0] != sent[0].ID || ids[1] != sent[0].ID {
+			t.Errorf("expected both IDs to be the sent ID %v, got: %v", sent[0].ID, ids)
+		}
+	})
+
+	t.Run("IDs are returned with errors", func(t *testing.T) {
+		client := NewClient(&MockTransport{
+			SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+				return &SendRequestOutput{}, nil
+			},
+		})
+
+		ids, err := client.InvokeBatchWithIDs(context.Background(), []MethodCaller{
+			&Invoke[[]int, string]{Name: "a", Request: []int{1}},
+		})
+
+		var emptyErr *EmptyResponseError
+		if !errors.As(err, &emptyErr) {
+			t.Fatalf("expected error type: *EmptyResponseError, got: %T", err)
+		}
+		if len(ids) != 1 || !ids[0].Equal(NewID(1)) {
+			t.Errorf("expected ID: 1, got: %v", ids)
+		}
+	})
+
+	t.Run("no IDs when the batch cannot be built", func(t *testing.T) {
+		client := NewClient(transport)
+		ids, err := client.InvokeBatchWithIDs(context.Background(), nil)
+		if err == nil || ids != nil {
+			t.Errorf("expected an error and nil IDs, got: %v and %v", err, ids)
+		}
+	})
+}
+
+func TestWithNoAutoID(t *testing.T) {
+	var sent []*JSONRPCRequest
+	transport := &MockTransport{
+		SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			sent = input.Requests
+			var responses []*JSONRPCResponse
+			for _, req := range input.Requests {
+				if !req.ID.IsNotification() {
+					responses = append(responses, &JSONRPCResponse{Version: "2.0", ID: req.ID, Result: json.RawMessage(`"ok"`)})
+				}
+			}
+			return &SendRequestOutput{Responses: responses}, nil
+		},
+	}
+	generated := 0
+	client := NewClient(transport, WithNoAutoID(), WithIDGenerator(func() *IDValue {
+		generated++
+		return NewID(generated)
+	}))
+
+	t.Run("nil ID is sent as a notification", func(t *testing.T) {
+		invoke := &Invoke[[]int, string]{Name: "test", Request: []int{1}}
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if !sent[0].ID.IsNotification() {
+			t.Errorf("expected a notification, got ID: %v", sent[0].ID)
+		}
+		if invoke.Response != "" {
+			t.Errorf("expected no response to be decoded, got: %s", invoke.Response)
+		}
+	})
+
+	t.Run("pre-assigned IDs are kept", func(t *testing.T) {
+		invoke := &Invoke[[]int, string]{ID: NewID("upstream-7"), Name: "test", Request: []int{1}}
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if !sent[0].ID.Equal(NewID("upstream-7")) {
+			t.Errorf("expected ID: upstream-7, got: %v", sent[0].ID)
+		}
+		if invoke.Response != "ok" {
+			t.Errorf("expected response: ok, got: %s", invoke.Response)
+		}
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		call := &Invoke[[]int, string]{ID: NewID(42), Name: "call", Request: []int{1}}
+		notify := &Invoke[[]int, string]{Name: "notify", Request: []int{2}}
+		if err := client.InvokeBatch(context.Background(), []MethodCaller{call, notify}); err != nil {
+			t.Fatalf("InvokeBatch error: %v", err)
+		}
+		if !sent[1].ID.IsNotification() {
+			t.Errorf("expected a notification, got ID: %v", sent[1].ID)
+		}
+		if call.Response != "ok" {
+			t.Errorf("expected response: ok, got: %s", call.Response)
+		}
+	})
+
+	t.Run("Call and Initialize send nothing", func(t *testing.T) {
+		sent = nil
+		_, err := client.Call(context.Background(), "test", []int{1})
+		var invalidErr *InvalidRequestError
+		if !errors.As(err, &invalidErr) {
+			t.Fatalf("expected error type: *InvalidRequestError, got: %T", err)
+		}
+		if _, err := client.Initialize(context.Background(), nil); !errors.As(err, &invalidErr) {
+			t.Fatalf("expected error type: *InvalidRequestError, got: %T", err)
+		}
+		if sent != nil {
+			t.Errorf("expected nothing to be sent, got: %v", sent)
+		}
+	})
+
+	if generated != 0 {
+		t.Errorf("expected no IDs to be generated, got: %d", generated)
+	}
+}
+
+func TestWithUnwrapStringResult(t *testing.T) {
+	respond := func(result string) *MockTransport {
+		return &MockTransport{
+			SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+				return &SendRequestOutput{Responses: []*JSONRPCResponse{
+					{Version: "2.0", ID: input.Requests[0].ID, Result: json.RawMessage(result)},
+				}}, nil
+			},
+		}
+	}
+	type point struct {
+		X int `json:"x"`
+	}
+
+	t.Run("double-encoded object", func(t *testing.T) {
+		client := NewClient(respond(`"{\"x\":1}"`), WithUnwrapStringResult())
+
+		invoke := &Invoke[[]int, point]{Name: "get", Request: []int{1}}
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if invoke.Response.X != 1 {
+			t.Errorf("expected x: 1, got: %d", invoke.Response.X)
+		}
+	})
+
+	t.Run("plain object", func(t *testing.T) {
+		client := NewClient(respond(`{"x":2}`), WithUnwrapStringResult())
+
+		invoke := &Invoke[[]int, point]{Name: "get", Request: []int{1}}
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if invoke.Response.X != 2 {
+			t.Errorf("expected x: 2, got: %d", invoke.Response.X)
+		}
+	})
+
+	t.Run("string result is not mangled", func(t *testing.T) {
+		client := NewClient(respond(`"{\"x\":1}"`), WithUnwrapStringResult())
+
+		invoke := &Invoke[[]int, string]{Name: "get", Request: []int{1}}
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if invoke.Response != `{"x":1}` {
+			t.Errorf("expected response: {\"x\":1}, got: %s", invoke.Response)
+		}
+	})
+
+	t.Run("interface result is not mangled", func(t *testing.T) {
+		client := NewClient(respond(`"hello"`), WithUnwrapStringResult())
+
+		invoke := &Invoke[[]int, any]{Name: "get", Request: []int{1}}
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if invoke.Response != "hello" {
+			t.Errorf("expected response: hello, got: %v", invoke.Response)
+		}
+	})
+
+	t.Run("time result is not mangled", func(t *testing.T) {
+		client := NewClient(respond(`"2024-05-01T12:00:00Z"`), WithUnwrapStringResult())
+
+		invoke := &Invoke[[]int, time.Time]{Name: "get", Request: []int{1}}
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if expected := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC); !invoke.Response.Equal(expected) {
+			t.Errorf("expected response: %v, got: %v", expected, invoke.Response)
+		}
+	})
+
+	t.Run("bytes result is not mangled", func(t *testing.T) {
+		client := NewClient(respond(`"aGVsbG8="`), WithUnwrapStringResult())
+
+		invoke := &Invoke[[]int, []byte]{Name: "get", Request: []int{1}}
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if string(invoke.Response) != "hello" {
+			t.Errorf("expected response: hello, got: %s", invoke.Response)
+		}
+	})
+
+	t.Run("invalid inner JSON", func(t *testing.T) {
+		client := NewClient(respond(`"{not json"`), WithUnwrapStringResult())
+
+		err := client.Invoke(context.Background(), &Invoke[[]int, point]{Name: "get", Request: []int{1}})
+
+		var unmarshalErr *UnmarshalError
+		if !errors.As(err, &unmarshalErr) {
+			t.Fatalf("expected error type: *UnmarshalError, got: %T", err)
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		client := NewClient(respond(`"{\"x\":1}"`))
+
+		err := client.Invoke(context.Background(), &Invoke[[]int, point]{Name: "get", Request: []int{1}})
+
+		var unmarshalErr *UnmarshalError
+		if !errors.As(err, &unmarshalErr) {
+			t.Fatalf("expected error type: *UnmarshalError, got: %T", err)
+		}
+	})
+}
+
+func TestInvokeTag(t *testing.T) {
+	type order struct{ number int }
+
+	var sent []*JSONRPCRequest
+	mock := &MockTransport{
+		SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			sent = input.Requests
+			output := &SendRequestOutput{}
+			for _, req := range input.Requests {
+				if req.Method == "fail" {
+					output.Responses = append(output.Responses, &JSONRPCResponse{Version: "2.0", ID: req.ID, Error: &JSONRPCError{Code: -32000, Message: "failed"}})
+				} else {
+					output.Responses = append(output.Responses, &JSONRPCResponse{Version: "2.0", ID: req.ID, Result: json.RawMessage(`"ok"`)})
+				}
+			}
+			return output, nil
+		},
+	}
+	client := NewClient(mock)
+
+	t.Run("not marshaled", func(t *testing.T) {
+		invoke := &Invoke[[]int, string]{Name: "test", Request: []int{1}, Tag: &order{number: 1}}
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if sent[0].Tag != invoke.Tag {
+			t.Errorf("expected the tag on the request, got: %v", sent[0].Tag)
+		}
+		data, err := json.Marshal(sent[0])
+		if err != nil {
+			t.Fatalf("Marshal error: %v", err)
+		}
+		if strings.Contains(string(data), "tag") || strings.Contains(string(data), "Tag") {
+			t.Errorf("expected the tag not to be marshaled, got: %s", data)
+		}
+	})
+
+	t.Run("attached to Invoke errors", func(t *testing.T) {
+		tag := &order{number: 2}
+		err := client.Invoke(context.Background(), &Invoke[[]int, string]{Name: "fail", Request: []int{1}, Tag: tag})
+
+		var tagged *TaggedError
+		if !errors.As(err, &tagged) {
+			t.Fatalf("expected error type: *TaggedError, got: %T", err)
+		}
+		if tagged.Tag != tag {
+			t.Errorf("expected tag: %v, got: %v", tag, tagged.Tag)
+		}
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) || rpcErr.ID == nil {
+			t.Errorf("expected a wrapped RPCError with its ID, got: %v", err)
+		}
+	})
+
+	t.Run("identifies the failed call in a batch", func(t *testing.T) {
+		first := &Invoke[[]int, string]{Name: "test", Request: []int{1}, Tag: &order{number: 3}}
+		second := &Invoke[[]int, string]{Name: "fail", Request: []int{2}, Tag: &order{number: 4}}
+		err := client.InvokeBatch(context.Background(), []MethodCaller{first, second})
+
+		var tagged *TaggedError
+		if !errors.As(err, &tagged) {
+			t.Fatalf("expected error type: *TaggedError, got: %T", err)
+		}
+		if tagged.Tag != second.Tag {
+			t.Errorf("expected tag: %v, got: %v", second.Tag, tagged.Tag)
+		}
+		if first.Response != "ok" {
+			t.Errorf("expected response: ok, got: %s", first.Response)
+		}
+	})
+
+	t.Run("untagged errors are not wrapped", func(t *testing.T) {
+		err := client.Invoke(context.Background(), &Invoke[[]int, string]{Name: "fail", Request: []int{1}})
+
+		if _, ok := err.(*RPCError); !ok {
+			t.Errorf("expected error type: *RPCError, got: %T", err)
+		}
+	})
+}
+
+func TestWithDecodeResultOnError(t *testing.T) {
+	type result struct {
+		Result string `json:"result"`
+	}
+	transport := &MockTransport{
+		SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			return &SendRequestOutput{Responses: []*JSONRPCResponse{{
+				Version: "2.0",
+				ID:      input.Requests[0].ID,
+				Result:  json.RawMessage(`{"result":"success"}`),
+				Error:   &JSONRPCError{Code: -32600, Message: "Invalid Request"},
+			}}}, nil
+		},
+	}
+
+	t.Run("result is decoded", func(t *testing.T) {
+		client := NewClient(transport, WithDecodeResultOnError())
+		invoke := &Invoke[[]int, result]{Name: "test", Request: []int{1}}
+		err := client.Invoke(context.Background(), invoke)
+
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) {
+			t.Fatalf("expected error type: *RPCError, got: %T", err)
+		}
+		if invoke.Response.Result != "success" {
+			t.Errorf("expected response: success, got: %s", invoke.Response.Result)
+		}
+	})
+
+	t.Run("undecodable result still returns the RPCError", func(t *testing.T) {
+		client := NewClient(transport, WithDecodeResultOnError())
+		err := client.Invoke(context.Background(), &Invoke[[]int, int]{Name: "test", Request: []int{1}})
+
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) {
+			t.Fatalf("expected error type: *RPCError, got: %T", err)
+		}
+	})
+
+	t.Run("default leaves the response untouched", func(t *testing.T) {
+		client := NewClient(transport)
+		invoke := &Invoke[[]int, result]{Name: "test", Request: []int{1}}
+		_ = client.Invoke(context.Background(), invoke)
+
+		if invoke.Response.Result != "" {
+			t.Errorf("expected an empty response, got: %s", invoke.Response.Result)
+		}
+	})
+}
+
+// point is sent as a positional [x, y] array through ParamsMarshaler
+type point struct {
+	X, Y int
+}
+
+func (p point) MarshalParams() (any, error) {
+	return []int{p.X, p.Y}, nil
+}
+
+// checkedParams implements ParamsMarshaler on its pointer and rejects negative limits
+type checkedParams struct {
+	Limit int `json:"limit"`
+}
+
+func (p *checkedParams) MarshalParams() (any, error) {
+	if p.Limit < 0 {
+		return nil, errors.New("limit must not be negative")
+	}
+	return map[string]int{"max": p.Limit}, nil
+}
+
+func TestParamsMarshaler(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"ok"}`))
+	}))
+	defer server.Close()
+	client := NewClient(NewHTTPTransport(server.URL))
+
+	t.Run("value receiver", func(t *testing.T) {
+		if err := client.Invoke(context.Background(), &Invoke[point, string]{ID: NewID(1), Name: "move", Request: point{X: 1, Y: 2}}); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if !strings.Contains(body, `"params":[1,2]`) {
+			t.Errorf("expected positional params, got: %s", body)
+		}
+	})
+
+	t.Run("pointer receiver", func(t *testing.T) {
+		if err := client.Invoke(context.Background(), &Invoke[checkedParams, string]{ID: NewID(1), Name: "list", Request: checkedParams{Limit: 5}}); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if !strings.Contains(body, `"params":{"max":5}`) {
+			t.Errorf("expected transformed params, got: %s", body)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		err := client.Invoke(context.Background(), &Invoke[checkedParams, string]{ID: NewID(1), Name: "list", Request: checkedParams{Limit: -1}})
+
+		var marshalErr *MarshalError
+		if !errors.As(err, &marshalErr) {
+			t.Fatalf("expected error type: *MarshalError, got: %T", err)
+		}
+		if !strings.Contains(err.Error(), "limit must not be negative") {
+			t.Errorf("expected the MarshalParams error, got: %v", err)
+		}
+	})
+
+	t.Run("DynBatch", func(t *testing.T) {
+		batch := NewDynBatch()
+		var result string
+		batch.Add("move", point{X: 3, Y: 4}, &result)
+		// The server answers with a single object, so only the request is checked
+		_ = client.InvokeBatch(context.Background(), batch.Calls())
+		if !strings.Contains(body, `"params":[3,4]`) {
+			t.Errorf("expected positional params in the batch, got: %s", body)
+		}
+	})
+}
+
+// shape decodes a result that is either a bare number (legacy servers) or {"kind":..,"size":..}
+type shape struct {
+	Kind string
+	Size int
+}
+
+func (s *shape) UnmarshalResult(result json.RawMessage) error {
+	if err := json.Unmarshal(result, &s.Size); err == nil {
+		s.Kind = "legacy"
+		return nil
+	}
+	var v struct {
+		Kind string `json:"kind"`
+		Size int    `json:"size"`
+	}
+	if err := json.Unmarshal(result, &v); err != nil {
+		return err
+	}
+	if v.Kind == "" {
+		return errors.New("missing kind")
+	}
+	s.Kind, s.Size = v.Kind, v.Size
+	return nil
+}
+
+func TestResultUnmarshaler(t *testing.T) {
+	var result string
+	client := NewClient(&MockTransport{
+		SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			return &SendRequestOutput{Responses: []*JSONRPCResponse{
+				{Version: "2.0", ID: input.Requests[0].ID, Result: json.RawMessage(result)},
+			}}, nil
+		},
+	}, WithUnwrapStringResult())
+
+	tests := []struct {
+		name     string
+		result   string
+		expected shape
+		wantErr  bool
+	}{
+		{name: "object", result: `{"kind":"square","size":2}`, expected: shape{Kind: "square", Size: 2}},
+		{name: "legacy number", result: `7`, expected: shape{Kind: "legacy", Size: 7}},
+		{name: "raw result is passed", result: `"{\"kind\":\"circle\"}"`, wantErr: true},
+		{name: "decoder error", result: `{"size":1}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result = tt.result
+			invoke := &Invoke[[]int, shape]{Name: "shape", Request: []int{1}}
+			err := client.Invoke(context.Background(), invoke)
+
+			if tt.wantErr {
+				var unmarshalErr *UnmarshalError
+				if !errors.As(err, &unmarshalErr) {
+					t.Fatalf("expected error type: *UnmarshalError, got: %T", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Invoke error: %v", err)
+			}
+			if invoke.Response != tt.expected {
+				t.Errorf("expected response: %+v, got: %+v", tt.expected, invoke.Response)
+			}
+		})
+	}
+}
+
+func TestInvokeReuse(t *testing.T) {
+	type result struct {
+		Name  string            `json:"name"`
+		Items map[string]string `json:"items"`
+	}
+	replies := []string{
+		`{"name":"first","items":{"a":"1"}}`,
+		`{"items":{"b":"2"}}`,
+	}
+	var sentIDs []string
+	client := NewClient(&MockTransport{
+		SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			request := input.Requests[0]
+			sentIDs = append(sentIDs, request.ID.String())
+			reply := replies[len(sentIDs)-1]
+			return &SendRequestOutput{Responses: []*JSONRPCResponse{
+				{Version: "2.0", ID: request.ID, Result: json.RawMessage(reply)},
+			}}, nil
+		},
+	})
+
+	invoke := &Invoke[[]int, result]{Name: "test", Request: []int{1}}
+	for range replies {
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+	}
+
+	if invoke.ID != nil {
+		t.Errorf("expected the ID to stay caller-owned, got: %v", invoke.ID)
+	}
+	if len(sentIDs) != 2 || sentIDs[0] == sentIDs[1] {
+		t.Errorf("expected a fresh ID for each call, got: %v", sentIDs)
+	}
+	expected := result{Items: map[string]string{"b": "2"}}
+	if !reflect.DeepEqual(invoke.Response, expected) {
+		t.Errorf("expected response: %+v, got: %+v", expected, invoke.Response)
+	}
+}
+
+func TestInvokeReuseAfterError(t *testing.T) {
+	replies := []func(request *JSONRPCRequest) (*SendRequestOutput, error){
+		func(request *JSONRPCRequest) (*SendRequestOutput, error) {
+			return &SendRequestOutput{Responses: []*JSONRPCResponse{{Version: "2.0", ID: request.ID, Result: json.RawMessage(`"first"`)}}}, nil
+		},
+		func(request *JSONRPCRequest) (*SendRequestOutput, error) {
+			return &SendRequestOutput{Responses: []*JSONRPCResponse{{Version: "2.0", ID: request.ID, Error: &JSONRPCError{Code: -32000, Message: "failed"}}}}, nil
+		},
+		func(request *JSONRPCRequest) (*SendRequestOutput, error) {
+			return &SendRequestOutput{}, nil
+		},
+		func(request *JSONRPCRequest) (*SendRequestOutput, error) {
+			return nil, &InvokeError{Method: request.Method, Err: errors.New("connection reset")}
+		},
+	}
+	calls := 0
+	client := NewClient(&MockTransport{
+		SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			calls++
+			return replies[(calls-1)%len(replies)](input.Requests[0])
+		},
+	})
+
+	for _, batch := range []bool{false, true} {
+		invoke := &Invoke[[]int, string]{Name: "test", Request: []int{1}}
+		for i := range replies {
+			var err error
+			if batch {
+				err = client.InvokeBatch(context.Background(), []MethodCaller{invoke})
+			} else {
+				err = client.Invoke(context.Background(), invoke)
+			}
+			if i == 0 {
+				if err != nil || invoke.Response != "first" {
+					t.Fatalf("batch %v: expected response: first, got: %q (%v)", batch, invoke.Response, err)
+				}
+				continue
+			}
+			if err == nil {
+				t.Fatalf("batch %v, call %d: expected an error", batch, i)
+			}
+			if invoke.Response != "" {
+				t.Errorf("batch %v, call %d: expected the response to be reset, got: %q", batch, i, invoke.Response)
+			}
+			// Refill as if the previous call had succeeded
+			invoke.Response = "stale"
+		}
+	}
+}
+
+func TestWithFixedIDGenerator(t *testing.T) {
+	var sent []string
+	transport := &MockTransport{
+		SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			output := &SendRequestOutput{}
+			for _, req := range input.Requests {
+				data, _ := json.Marshal(req)
+				sent = append(sent, string(data))
+				output.Responses = append(output.Responses, &JSONRPCResponse{Version: "2.0", ID: req.ID, Result: json.RawMessage(`"ok"`)})
+			}
+			return output, nil
+		},
+	}
+	client := NewClient(transport, WithFixedIDGenerator(NewID("a"), NewID(7), NewID("c")))
+
+	if err := client.Invoke(context.Background(), &Invoke[[]int, string]{Name: "first", Request: []int{1}}); err != nil {
+		t.Fatalf("Invoke error: %v", err)
+	}
+	err := client.InvokeBatch(context.Background(), []MethodCaller{
+		&Invoke[[]int, string]{Name: "second", Request: []int{2}},
+		&Invoke[[]int, string]{Name: "third", Request: []int{3}},
+	})
+	if err != nil {
+		t.Fatalf("InvokeBatch error: %v", err)
+	}
+
+	expected := []string{
+		`{"jsonrpc":"2.0","id":"a","method":"first","params":[1]}`,
+		`{"jsonrpc":"2.0","id":7,"method":"second","params":[2]}`,
+		`{"jsonrpc":"2.0","id":"c","method":"third","params":[3]}`,
+	}
+	if !reflect.DeepEqual(sent, expected) {
+		t.Errorf("expected requests: %v, got: %v", expected, sent)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected a panic once the IDs are exhausted")
+		}
+	}()
+	_ = client.Invoke(context.Background(), &Invoke[[]int, string]{Name: "fourth", Request: []int{4}})
+}
+
+func TestWithTreatEmptyObjectAsEmptyResult(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+	var result string
+	transport := &MockTransport{
+		SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			return &SendRequestOutput{Responses: []*JSONRPCResponse{
+				{Version: "2.0", ID: input.Requests[0].ID, Result: json.RawMessage(result)},
+			}}, nil
+		},
+	}
+
+	tests := []struct {
+		name    string
+		result  string
+		wantErr bool
+	}{
+		{name: "empty object", result: `{}`, wantErr: true},
+		{name: "empty object with whitespace", result: `{ }`, wantErr: true},
+		{name: "empty array", result: `[]`, wantErr: true},
+		{name: "populated object", result: `{"name":"alice"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result = tt.result
+			strict := NewClient(transport, WithTreatEmptyObjectAsEmptyResult())
+			err := strict.Invoke(context.Background(), &Invoke[[]int, user]{Name: "getUser", Request: []int{1}})
+
+			var emptyErr *EmptyResultError
+			if tt.wantErr != errors.As(err, &emptyErr) {
+				t.Errorf("expected EmptyResultError: %v, got: %v", tt.wantErr, err)
+			}
+
+			// The default stays lenient
+			if err := NewClient(transport).Invoke(context.Background(), &Invoke[[]int, any]{Name: "getUser", Request: []int{1}}); err != nil {
+				t.Errorf("expected no error by default, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("RawInvoke and DynBatch", func(t *testing.T) {
+		result = `{}`
+		client := NewClient(transport, WithTreatEmptyObjectAsEmptyResult())
+
+		var emptyErr *EmptyResultError
+		raw := FromRequest[user](&JSONRPCRequest{Method: "getUser"})
+		if err := client.Invoke(context.Background(), raw); !errors.As(err, &emptyErr) {
+			t.Errorf("expected error type: *EmptyResultError, got: %T", err)
+		}
+
+		batch := NewDynBatch()
+		var u user
+		batch.Add("getUser", []int{1}, &u)
+		if err := client.InvokeBatch(context.Background(), batch.Calls()); !errors.As(err, &emptyErr) {
+			t.Errorf("expected error type: *EmptyResultError, got: %T", err)
+		}
+	})
+}
+
+func TestInvokeSingleNotification(t *testing.T) {
+	t.Run("returns nil without a response", func(t *testing.T) {
+		var sent []*JSONRPCRequest
+		client := NewClient(&MockTransport{
+			SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+				sent = input.Requests
+				return nil, nil
+			},
+		})
+
+		invoke := AsNotification(&Invoke[[]int, string]{Name: "log", Request: []int{1}})
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if len(sent) != 1 || !sent[0].ID.IsNotification() {
+			t.Errorf("expected one notification to be sent, got: %v", sent)
+		}
+	})
+
+	t.Run("returns the transport error", func(t *testing.T) {
+		failure := &InvokeError{Method: "log", Err: errors.New("connection refused")}
+		client := NewClient(&MockTransport{
+			SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+				return nil, failure
+			},
+		})
+
+		err := client.Invoke(context.Background(), AsNotification(&Invoke[[]int, string]{Name: "log", Request: []int{1}}))
+		if !errors.Is(err, failure) {
+			t.Errorf("expected the transport error, got: %v", err)
+		}
+	})
+}
+
+func TestSendRaw(t *testing.T) {
+	var sent *SendRequestInput
+	transport := &MockTransport{
+		SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			sent = input
+			output := &SendRequestOutput{}
+			for _, req := range input.Requests {
+				if req.ID.IsNotification() {
+					continue
+				}
+				if req.Method == "fail" {
+					output.Responses = append(output.Responses, &JSONRPCResponse{Version: "2.0", ID: req.ID, Error: &JSONRPCError{Code: -32000, Message: "failed"}})
+					continue
+				}
+				output.Responses = append(output.Responses, &JSONRPCResponse{Version: "2.0", ID: req.ID, Result: json.RawMessage(`"ok"`)})
+			}
+			return output, nil
+		},
+	}
+
+	t.Run("batch is sent as built", func(t *testing.T) {
+		var transformed []string
+		client := NewClient(transport, WithVersion("1.0"), WithRequestTransform(func(req *JSONRPCRequest) error {
+			transformed = append(transformed, req.Method)
+			return nil
+		}))
+		reqs := []*JSONRPCRequest{
+			{Version: "2.0", ID: NewID("replay-1"), Method: "ok", Params: []int{1}},
+			{Version: "2.0", ID: NewID(42), Method: "fail"},
+			{Version: "2.0", ID: NewNotificationID(), Method: "log"},
+		}
+
+		responses, err := client.SendRaw(context.Background(), reqs, true)
+		if err != nil {
+			t.Fatalf("SendRaw error: %v", err)
+		}
+		if !sent.Batch || len(sent.Requests) != 3 || sent.Requests[0].ID.String() != "replay-1" || sent.Requests[1].Version != "2.0" {
+			t.Errorf("expected the requests to be sent unchanged, got: %+v", sent.Requests)
+		}
+		if sent.Requests[0] == reqs[0] {
+			t.Error("expected the requests to be copied")
+		}
+		if len(transformed) != 3 {
+			t.Errorf("expected the request transform to run for each request, got: %v", transformed)
+		}
+		if len(responses) != 2 || responses[1].Error == nil || responses[1].Error.Message != "failed" {
+			t.Errorf("expected raw responses with the error kept, got: %+v", responses)
+		}
+	})
+
+	t.Run("single request", func(t *testing.T) {
+		client := NewClient(transport)
+		responses, err := client.SendRaw(context.Background(), []*JSONRPCRequest{{Version: "2.0", ID: NewID(1), Method: "ok"}}, false)
+		if err != nil {
+			t.Fatalf("SendRaw error: %v", err)
+		}
+		if sent.Batch || len(responses) != 1 || string(responses[0].Result) != `"ok"` {
+			t.Errorf("expected a single raw response, got: %+v", responses)
+		}
+	})
+
+	t.Run("response transform", func(t *testing.T) {
+		client := NewClient(transport, WithResponseTransform(func(resp *JSONRPCResponse) (*JSONRPCResponse, error) {
+			return nil, errors.New("bad envelope")
+		}))
+		_, err := client.SendRaw(context.Background(), []*JSONRPCRequest{{Version: "2.0", ID: NewID(1), Method: "ok"}}, false)
+
+		var unmarshalErr *UnmarshalError
+		if !errors.As(err, &unmarshalErr) || unmarshalErr.Method != "ok" {
+			t.Errorf("expected an UnmarshalError for ok, got: %v", err)
+		}
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		client := NewClient(transport)
+		inputs := [][]*JSONRPCRequest{
+			nil,
+			{{Method: "a"}, {Method: "b"}},
+			{nil},
+		}
+		for i, reqs := range inputs {
+			var invalidErr *InvalidRequestError
+			if _, err := client.SendRaw(context.Background(), reqs, i == 2); !errors.As(err, &invalidErr) {
+				t.Errorf("input %d: expected error type: *InvalidRequestError, got: %T", i, err)
+			}
+		}
+	})
+}
+
+func TestWithStringIDs(t *testing.T) {
+	var sent []string
+	transport := &MockTransport{
+		SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			output := &SendRequestOutput{}
+			for _, req := range input.Requests {
+				data, _ := json.Marshal(req)
+				sent = append(sent, string(data))
+				output.Responses = append(output.Responses, &JSONRPCResponse{Version: "2.0", ID: req.ID, Result: json.RawMessage(`"ok"`)})
+			}
+			return output, nil
+		},
+	}
+	client := NewClient(transport, WithStringIDs())
+
+	first := &Invoke[[]int, string]{Name: "first", Request: []int{1}}
+	second := &Invoke[[]int, string]{Name: "second", Request: []int{2}}
+	explicit := &Invoke[[]int, string]{Name: "explicit", Request: []int{3}, ID: NewID(42)}
+	if err := client.InvokeBatch(context.Background(), []MethodCaller{first, second, explicit}); err != nil {
+		t.Fatalf("InvokeBatch error: %v", err)
+	}
+	if first.Response != "ok" || second.Response != "ok" {
+		t.Errorf("expected responses: ok and ok, got: %s and %s", first.Response, second.Response)
+	}
+
+	expected := []string{
+		`{"jsonrpc":"2.0","id":"1","method":"first","params":[1]}`,
+		`{"jsonrpc":"2.0","id":"2","method":"second","params":[2]}`,
+		`{"jsonrpc":"2.0","id":42,"method":"explicit","params":[3]}`,
+	}
+	if !reflect.DeepEqual(sent, expected) {
+		t.Errorf("expected requests: %v, got: %v", expected, sent)
+	}
+}
+
+func TestWithRequestMeta(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		var req JSONRPCRequest
+		_ = json.Unmarshal(body, &req)
+		_ = json.NewEncoder(w).Encode(&JSONRPCResponse{Version: "2.0", ID: req.ID, Result: json.RawMessage(`"ok"`)})
+	}))
+	defer server.Close()
+
+	client := NewClient(NewHTTPTransport(server.URL), WithRequestMeta(func(method string) any {
+		if method == "plain" {
+			return nil
+		}
+		return map[string]string{"trace": "t-1", "method": method}
+	}))
+
+	for _, name := range []string{"traced", "plain"} {
+		if err := client.Invoke(context.Background(), &Invoke[[]int, string]{Name: name, Request: []int{1}}); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+	}
+
+	expected := []string{
+		`{"jsonrpc":"2.0","id":1,"method":"traced","params":[1],"meta":{"method":"traced","trace":"t-1"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"plain","params":[1]}`,
+	}
+	if !reflect.DeepEqual(bodies, expected) {
+		t.Errorf("expected requests: %q, got: %q", expected, bodies)
+	}
+}
+
+func TestNullResponseBody(t *testing.T) {
+	newClient := func(body string) *Client {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(body))
+		}))
+		t.Cleanup(server.Close)
+		return NewClient(NewHTTPTransport(server.URL))
+	}
+
+	t.Run("single call", func(t *testing.T) {
+		err := newClient(`null`).Invoke(context.Background(), &Invoke[[]int, string]{Name: "get", Request: []int{1}})
+
+		var emptyErr *EmptyResponseError
+		if !errors.As(err, &emptyErr) {
+			t.Fatalf("expected error type: *EmptyResponseError, got: %T (%v)", err, err)
+		}
+	})
+
+	t.Run("batch element", func(t *testing.T) {
+		err := newClient(`[null]`).InvokeBatch(context.Background(), []MethodCaller{
+			&Invoke[[]int, string]{Name: "get", Request: []int{1}},
+		})
+
+		var missingErr *MissingResponseError
+		if !errors.As(err, &missingErr) {
+			t.Fatalf("expected error type: *MissingResponseError, got: %T (%v)", err, err)
+		}
+	})
+
+	t.Run("nil response from a decoder", func(t *testing.T) {
+		client := NewClient(&MockTransport{
+			SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+				return &SendRequestOutput{Responses: []*JSONRPCResponse{nil}}, nil
+			},
+		})
+		raw := FromRequest[string](&JSONRPCRequest{Method: "get"})
+		err := client.Invoke(context.Background(), raw)
+
+		var emptyErr *EmptyResponseError
+		if !errors.As(err, &emptyErr) {
+			t.Fatalf("expected error type: *EmptyResponseError, got: %T (%v)", err, err)
+		}
+	})
+}
@@ -0,0 +1,265 @@
+// Command jsonrpc-gen generates a JSON-RPC client implementation for a Go interface.
+//
+// Each interface method must have the form
+//
+//	Method(ctx context.Context[, params P]) ([R, ]error)
+//
+// and is mapped to a JSON-RPC method named by a "//jsonrpc:method <name>" comment,
+// or by the Go method name when the comment is absent. For example:
+//
+//	//go:generate jsonrpc-gen -type Calculator
+//	type Calculator interface {
+//		//jsonrpc:method add
+//		Add(ctx context.Context, params AddParams) (int, error)
+//	}
+//
+// generates a CalculatorClient type with a NewCalculatorClient constructor that
+// implements Calculator on top of a *jsonrpc_client.Client.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	importPath      = "github.com/yacchi/go-jsonrpc-client"
+	importName      = "jsonrpc"
+	methodDirective = "//jsonrpc:method "
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of interface names; required")
+	input := flag.String("input", os.Getenv("GOFILE"), "Go source file containing the interfaces")
+	output := flag.String("output", "", "output file name; default <input>_jsonrpc.go")
+	flag.Parse()
+
+	if *typeNames == "" || *input == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	src, err := os.ReadFile(*input)
+	if err != nil {
+		fatal(err)
+	}
+	code, err := generate(*input, src, strings.Split(*typeNames, ","))
+	if err != nil {
+		fatal(err)
+	}
+
+	if *output == "" {
+		*output = strings.TrimSuffix(*input, filepath.Ext(*input)) + "_jsonrpc.go"
+	}
+	if err := os.WriteFile(*output, code, 0o644); err != nil {
+		fatal(err)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "jsonrpc-gen: %v\n", err)
+	os.Exit(1)
+}
+
+// method describes one interface method to generate
+type method struct {
+	GoName  string
+	RPCName string
+	Params  string // empty when the method takes only a context
+	Result  string // empty when the method returns only an error
+}
+
+// generate returns the formatted client source for the named interfaces in src
+func generate(filename string, src []byte, typeNames []string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	interfaces := make(map[string]*ast.InterfaceType)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if spec, ok := n.(*ast.TypeSpec); ok {
+			if iface, ok := spec.Type.(*ast.InterfaceType); ok {
+				interfaces[spec.Name.Name] = iface
+			}
+		}
+		return true
+	})
+
+	used := make(map[string]bool)
+	body := bytes.NewBuffer(nil)
+	for _, name := range typeNames {
+		name = strings.TrimSpace(name)
+		iface, ok := interfaces[name]
+		if !ok {
+			return nil, fmt.Errorf("interface %s not found in %s", name, filename)
+		}
+		methods, err := collectMethods(fset, name, iface, used)
+		if err != nil {
+			return nil, err
+		}
+		writeClient(body, name, methods)
+	}
+
+	out := bytes.NewBuffer(nil)
+	fmt.Fprintf(out, "// Code generated by jsonrpc-gen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(out, "package %s\n\n", file.Name.Name)
+	fmt.Fprintf(out, "import (\n")
+	fmt.Fprintf(out, "\t\"context\"\n")
+	if used["json"] {
+		fmt.Fprintf(out, "\t\"encoding/json\"\n")
+	}
+	for _, imp := range usedImports(file, used) {
+		fmt.Fprintf(out, "\t%s\n", imp)
+	}
+	fmt.Fprintf(out, "\n\t%s %q\n", importName, importPath)
+	fmt.Fprintf(out, ")\n")
+	out.Write(body.Bytes())
+
+	return format.Source(out.Bytes())
+}
+
+// collectMethods validates the interface methods and records the package names their types use
+func collectMethods(fset *token.FileSet, ifaceName string, iface *ast.InterfaceType, used map[string]bool) ([]method, error) {
+	var methods []method
+	for _, field := range iface.Methods.List {
+		fn, ok := field.Type.(*ast.FuncType)
+		if !ok || len(field.Names) == 0 {
+			return nil, fmt.Errorf("%s: embedded interfaces are not supported", ifaceName)
+		}
+		m := method{GoName: field.Names[0].Name, RPCName: field.Names[0].Name}
+		if field.Doc != nil {
+			for _, c := range field.Doc.List {
+				if strings.HasPrefix(c.Text, methodDirective) {
+					m.RPCName = strings.TrimSpace(strings.TrimPrefix(c.Text, methodDirective))
+				}
+			}
+		}
+
+		params := flatten(fn.Params)
+		if len(params) == 0 || len(params) > 2 || exprString(fset, params[0]) != "context.Context" {
+			return nil, fmt.Errorf("%s.%s: expected parameters (ctx context.Context[, params P])", ifaceName, m.GoName)
+		}
+		if len(params) == 2 {
+			m.Params = exprString(fset, params[1])
+			collectPackages(params[1], used)
+		}
+
+		results := flatten(fn.Results)
+		if len(results) == 0 || len(results) > 2 || exprString(fset, results[len(results)-1]) != "error" {
+			return nil, fmt.Errorf("%s.%s: expected results ([R, ]error)", ifaceName, m.GoName)
+		}
+		if len(results) == 2 {
+			m.Result = exprString(fset, results[0])
+			collectPackages(results[0], used)
+		} else {
+			used["json"] = true
+		}
+		methods = append(methods, m)
+	}
+	return methods, nil
+}
+
+// flatten expands a field list so that "a, b int" yields two entries
+func flatten(fields *ast.FieldList) []ast.Expr {
+	if fields == nil {
+		return nil
+	}
+	var exprs []ast.Expr
+	for _, field := range fields.List {
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			exprs = append(exprs, field.Type)
+		}
+	}
+	return exprs
+}
+
+func exprString(fset *token.FileSet, expr ast.Expr) string {
+	buf := bytes.NewBuffer(nil)
+	printer.Fprint(buf, fset, expr)
+	return buf.String()
+}
+
+// collectPackages records the package qualifiers referenced by expr
+func collectPackages(expr ast.Expr, used map[string]bool) {
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+}
+
+// usedImports returns the import specs of file whose package names are in used
+func usedImports(file *ast.File, used map[string]bool) []string {
+	var imports []string
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		if path == "context" || path == "encoding/json" || path == importPath {
+			continue
+		}
+		name := filepath.Base(path)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if !used[name] {
+			continue
+		}
+		if spec.Name != nil {
+			imports = append(imports, spec.Name.Name+" "+spec.Path.Value)
+		} else {
+			imports = append(imports, spec.Path.Value)
+		}
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+// writeClient writes the client type, constructor and methods for one interface
+func writeClient(w *bytes.Buffer, ifaceName string, methods []method) {
+	typeName := ifaceName + "Client"
+	fmt.Fprintf(w, "\n// %s implements %s over JSON-RPC\n", typeName, ifaceName)
+	fmt.Fprintf(w, "type %s struct {\n\tclient *%s.Client\n}\n", typeName, importName)
+	fmt.Fprintf(w, "\nvar _ %s = (*%s)(nil)\n", ifaceName, typeName)
+	fmt.Fprintf(w, "\n// New%s creates a %s that sends calls through client\n", typeName, typeName)
+	fmt.Fprintf(w, "func New%s(client *%s.Client) *%s {\n\treturn &%s{client: client}\n}\n", typeName, importName, typeName, typeName)
+
+	for _, m := range methods {
+		params, request := "any", ""
+		signature := "ctx context.Context"
+		if m.Params != "" {
+			params, request = m.Params, "\n\t\tRequest: params,"
+			signature += ", params " + m.Params
+		}
+
+		fmt.Fprintf(w, "\n// %s calls the %q method\n", m.GoName, m.RPCName)
+		if m.Result == "" {
+			fmt.Fprintf(w, "func (c *%s) %s(%s) error {\n", typeName, m.GoName, signature)
+			fmt.Fprintf(w, "\tinvoke := &%s.Invoke[%s, json.RawMessage]{\n\t\tName: %q,%s\n\t}\n", importName, params, m.RPCName, request)
+			fmt.Fprintf(w, "\treturn c.client.Invoke(ctx, invoke)\n}\n")
+			continue
+		}
+		fmt.Fprintf(w, "func (c *%s) %s(%s) (%s, error) {\n", typeName, m.GoName, signature, m.Result)
+		fmt.Fprintf(w, "\tinvoke := &%s.Invoke[%s, %s]{\n\t\tName: %q,%s\n\t}\n", importName, params, m.Result, m.RPCName, request)
+		fmt.Fprintf(w, "\tif err := c.client.Invoke(ctx, invoke); err != nil {\n\t\tvar zero %s\n\t\treturn zero, err\n\t}\n", m.Result)
+		fmt.Fprintf(w, "\treturn invoke.Response, nil\n}\n")
+	}
+}
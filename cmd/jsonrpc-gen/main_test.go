@@ -0,0 +1,132 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSource = `package calc
+
+import (
+	"context"
+	"time"
+	"net/url"
+)
+
+type AddParams struct {
+	A int ` + "`json:\"a\"`" + `
+	B int ` + "`json:\"b\"`" + `
+}
+
+type Calculator interface {
+	//jsonrpc:method add
+	Add(ctx context.Context, params AddParams) (int, error)
+
+	// Now returns the server time
+	//jsonrpc:method clock.now
+	Now(ctx context.Context) (time.Time, error)
+
+	Reset(ctx context.Context, params []string) error
+}
+
+var _ = url.Parse
+`
+
+func TestGenerate(t *testing.T) {
+	code, err := generate("calc.go", []byte(testSource), []string{"Calculator"})
+	if err != nil {
+		t.Fatalf("generate error: %v", err)
+	}
+	out := string(code)
+
+	expected := []string{
+		"// Code generated by jsonrpc-gen; DO NOT EDIT.",
+		"type CalculatorClient struct",
+		"func NewCalculatorClient(client *jsonrpc.Client) *CalculatorClient",
+		`invoke := &jsonrpc.Invoke[AddParams, int]{`,
+		`Name:    "add",`,
+		`invoke := &jsonrpc.Invoke[any, time.Time]{`,
+		`Name: "clock.now",`,
+		`invoke := &jsonrpc.Invoke[[]string, json.RawMessage]{`,
+		`Name:    "Reset",`,
+		`"time"`,
+	}
+	for _, want := range expected {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, `"net/url"`) {
+		t.Errorf("expected unused import net/url to be dropped\n%s", out)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{
+			name:   "missing context",
+			source: "package p\ntype S interface { Do(x int) (int, error) }",
+		},
+		{
+			name:   "missing error result",
+			source: "package p\nimport \"context\"\ntype S interface { Do(ctx context.Context) int }",
+		},
+		{
+			name:   "too many params",
+			source: "package p\nimport \"context\"\ntype S interface { Do(ctx context.Context, a, b int) error }",
+		},
+		{
+			name:   "unknown interface",
+			source: "package p\ntype Other interface{}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := generate("p.go", []byte(tt.source), []string{"S"}); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestGenerateCompiles(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not available")
+	}
+	root, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, err := generate("calc.go", []byte(testSource), []string{"Calculator"})
+	if err != nil {
+		t.Fatalf("generate error: %v", err)
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":          "module calc\n\ngo 1.22\n\nrequire github.com/yacchi/go-jsonrpc-client v0.0.0\n\nreplace github.com/yacchi/go-jsonrpc-client => " + root + "\n",
+		"calc.go":         testSource,
+		"calc_jsonrpc.go": string(code),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := exec.Command(goBin, "build", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generated code does not compile: %v\n%s\n%s", err, out, code)
+	}
+}
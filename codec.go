@@ -0,0 +1,101 @@
+package jsonrpc_client
+
+import (
+	"bytes"
+	"io"
+)
+
+// RequestEncoder controls how requests are written to the wire. It allows the client to be
+// used with JSON-RPC-like protocols whose envelope differs from the standard request shape.
+// For batches, input.Batch is true and input.Requests holds every request of the batch.
+type RequestEncoder interface {
+	EncodeRequest(w io.Writer, input *SendRequestInput) error
+}
+
+// ResponseDecoder controls how responses are read from the wire. It is the counterpart of
+// RequestEncoder and must return one JSONRPCResponse per response found in r.
+type ResponseDecoder interface {
+	DecodeResponse(r io.Reader, input *SendRequestInput) ([]*JSONRPCResponse, error)
+}
+
+// RequestEncoderFunc is an adapter to allow the use of ordinary functions as RequestEncoder
+type RequestEncoderFunc func(w io.Writer, input *SendRequestInput) error
+
+// EncodeRequest calls f(w, input)
+func (f RequestEncoderFunc) EncodeRequest(w io.Writer, input *SendRequestInput) error {
+	return f(w, input)
+}
+
+// ResponseDecoderFunc is an adapter to allow the use of ordinary functions as ResponseDecoder
+type ResponseDecoderFunc func(r io.Reader, input *SendRequestInput) ([]*JSONRPCResponse, error)
+
+// DecodeResponse calls f(r, input)
+func (f ResponseDecoderFunc) DecodeResponse(r io.Reader, input *SendRequestInput) ([]*JSONRPCResponse, error) {
+	return f(r, input)
+}
+
+// WithRequestEncoder replaces the standard JSON-RPC request encoding of the HTTP transport.
+// It takes precedence over WithFormEncodedParams.
+func WithRequestEncoder(encoder RequestEncoder) HTTPTransportOption {
+	return func(t *HTTPTransport) {
+		t.encoder = encoder
+	}
+}
+
+// WithResponseDecoder replaces the standard JSON-RPC response decoding of the HTTP transport.
+// When a response envelope is also configured, the decoder receives the extracted value.
+func WithResponseDecoder(decoder ResponseDecoder) HTTPTransportOption {
+	return func(t *HTTPTransport) {
+		t.decoder = decoder
+	}
+}
+
+// EncodeRequest encodes requests exactly as the HTTP transport sends them: a JSON array when
+// batch is set, otherwise the single request as an object. The result can be built once and
+// sent through several transports. An encoding failure is reported as MarshalError.
+func EncodeRequest(requests []*JSONRPCRequest, batch bool) ([]byte, error) {
+	if len(requests) == 0 {
+		return nil, &InvalidRequestError{Message: "no request provided"}
+	}
+	if !batch && len(requests) > 1 {
+		return nil, &InvalidRequestError{Message: "only a batch can hold more than one request"}
+	}
+	var buf bytes.Buffer
+	if err := encodeRequests(&buf, requests, batch, encodeJSON); err != nil {
+		return nil, &MarshalError{Method: requests[0].Method, Err: err}
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeResponse decodes a response body exactly as the HTTP transport does: with batch set,
+// a JSON array of responses, tolerating malformed elements, or a single object answering
+// the batch; otherwise a single response. A decoding failure is reported as UnmarshalError.
+func DecodeResponse(b []byte, batch bool) ([]*JSONRPCResponse, error) {
+	responses, err := decodeResponses(bytes.NewReader(b), batch, "")
+	if err != nil {
+		return nil, &UnmarshalError{Err: err}
+	}
+	return responses, nil
+}
+
+// encodeRequests writes requests with encode, as an array for a batch and as the first
+// request otherwise
+func encodeRequests(buf *bytes.Buffer, requests []*JSONRPCRequest, batch bool, encode func(*bytes.Buffer, any) error) error {
+	if batch {
+		return encode(buf, requests)
+	}
+	return encode(buf, requests[0])
+}
+
+// decodeResponses reads a batch response or a single response from r. A non-empty idField
+// names the member that carries the request ID in place of "id".
+func decodeResponses(r io.Reader, batch bool, idField string) ([]*JSONRPCResponse, error) {
+	if batch {
+		return decodeBatchResponses(r, idField)
+	}
+	response, err := decodeResponse(r, idField)
+	if err != nil {
+		return nil, err
+	}
+	return []*JSONRPCResponse{response}, nil
+}
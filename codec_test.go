@@ -0,0 +1,281 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// callDialect is a JSON-RPC-like protocol that nests the method under "call"
+type callDialect struct{}
+
+type callRequest struct {
+	ID   *IDValue `json:"id,omitzero"`
+	Call struct {
+		Name string `json:"name"`
+		Args any    `json:"args,omitempty"`
+	} `json:"call"`
+}
+
+type callResponse struct {
+	ID    *IDValue        `json:"id"`
+	Ok    json.RawMessage `json:"ok,omitempty"`
+	Fault *JSONRPCError   `json:"fault,omitempty"`
+}
+
+func (callDialect) EncodeRequest(w io.Writer, input *SendRequestInput) error {
+	calls := make([]callRequest, len(input.Requests))
+	for i, req := range input.Requests {
+		calls[i].ID = req.ID
+		calls[i].Call.Name = req.Method
+		calls[i].Call.Args = req.Params
+	}
+	if input.Batch {
+		return json.NewEncoder(w).Encode(calls)
+	}
+	return json.NewEncoder(w).Encode(calls[0])
+}
+
+func (callDialect) DecodeResponse(r io.Reader, input *SendRequestInput) ([]*JSONRPCResponse, error) {
+	var results []callResponse
+	if input.Batch {
+		if err := json.NewDecoder(r).Decode(&results); err != nil {
+			return nil, err
+		}
+	} else {
+		results = make([]callResponse, 1)
+		if err := json.NewDecoder(r).Decode(&results[0]); err != nil {
+			return nil, err
+		}
+	}
+
+	responses := make([]*JSONRPCResponse, len(results))
+	for i, res := range results {
+		responses[i] = &JSONRPCResponse{ID: res.ID, Result: res.Ok, Error: res.Fault}
+	}
+	return responses, nil
+}
+
+func TestHTTPTransportCustomCodec(t *testing.T) {
+	newServer := func(t *testing.T, handle func(req callRequest) callResponse) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			var batch []callRequest
+			if json.Unmarshal(body, &batch) == nil {
+				results := make([]callResponse, len(batch))
+				for i, req := range batch {
+					results[i] = handle(req)
+				}
+				json.NewEncoder(w).Encode(results)
+				return
+			}
+			var req callRequest
+			if err := json.Unmarshal(body, &req); err != nil {
+				t.Errorf("failed to decode request: %v", err)
+				return
+			}
+			json.NewEncoder(w).Encode(handle(req))
+		}))
+	}
+
+	add := func(req callRequest) callResponse {
+		if req.Call.Name != "add" {
+			return callResponse{ID: req.ID, Fault: &JSONRPCError{Code: -32601, Message: "unknown call"}}
+		}
+		args := req.Call.Args.([]any)
+		sum := args[0].(float64) + args[1].(float64)
+		result, _ := json.Marshal(sum)
+		return callResponse{ID: req.ID, Ok: result}
+	}
+
+	t.Run("single request", func(t *testing.T) {
+		server := newServer(t, add)
+		defer server.Close()
+
+		client := NewClient(NewHTTPTransport(server.URL,
+			WithRequestEncoder(callDialect{}),
+			WithResponseDecoder(callDialect{}),
+		))
+
+		invoke := &Invoke[[]int, int]{Name: "add", Request: []int{1, 2}}
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if invoke.Response != 3 {
+			t.Errorf("expected result: 3, got: %d", invoke.Response)
+		}
+	})
+
+	t.Run("batch request", func(t *testing.T) {
+		server := newServer(t, add)
+		defer server.Close()
+
+		client := NewClient(NewHTTPTransport(server.URL,
+			WithRequestEncoder(callDialect{}),
+			WithResponseDecoder(callDialect{}),
+		))
+
+		first := &Invoke[[]int, int]{Name: "add", Request: []int{1, 2}}
+		second := &Invoke[[]int, int]{Name: "add", Request: []int{3, 4}}
+		if err := client.InvokeBatch(context.Background(), []MethodCaller{first, second}); err != nil {
+			t.Fatalf("InvokeBatch error: %v", err)
+		}
+		if first.Response != 3 || second.Response != 7 {
+			t.Errorf("expected results: 3 and 7, got: %d and %d", first.Response, second.Response)
+		}
+	})
+
+	t.Run("error response", func(t *testing.T) {
+		server := newServer(t, add)
+		defer server.Close()
+
+		client := NewClient(NewHTTPTransport(server.URL,
+			WithRequestEncoder(callDialect{}),
+			WithResponseDecoder(callDialect{}),
+		))
+
+		err := client.Invoke(context.Background(), &Invoke[[]int, int]{Name: "sub", Request: []int{1, 2}})
+
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) {
+			t.Fatalf("expected error type: *RPCError, got: %T", err)
+		}
+		if rpcErr.Code != -32601 {
+			t.Errorf("expected code: -32601, got: %d", rpcErr.Code)
+		}
+	})
+
+	t.Run("encoder error", func(t *testing.T) {
+		encoder := RequestEncoderFunc(func(w io.Writer, input *SendRequestInput) error {
+			return errors.New("encode failed")
+		})
+		transport := NewHTTPTransport("http://127.0.0.1:0", WithRequestEncoder(encoder))
+
+		_, err := transport.SendRequest(context.Background(), &SendRequestInput{
+			Requests: []*JSONRPCRequest{{Version: "2.0", ID: NewID(1), Method: "add"}},
+		})
+
+		var marshalErr *MarshalError
+		if !errors.As(err, &marshalErr) {
+			t.Fatalf("expected error type: *MarshalError, got: %T", err)
+		}
+	})
+
+	t.Run("decoder error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{}`))
+		}))
+		defer server.Close()
+
+		decoder := ResponseDecoderFunc(func(r io.Reader, input *SendRequestInput) ([]*JSONRPCResponse, error) {
+			return nil, errors.New("decode failed")
+		})
+		transport := NewHTTPTransport(server.URL, WithResponseDecoder(decoder))
+
+		_, err := transport.SendRequest(context.Background(), &SendRequestInput{
+			Requests: []*JSONRPCRequest{{Version: "2.0", ID: NewID(1), Method: "add"}},
+		})
+
+		var unmarshalErr *UnmarshalError
+		if !errors.As(err, &unmarshalErr) {
+			t.Fatalf("expected error type: *UnmarshalError, got: %T", err)
+		}
+	})
+}
+
+func TestEncodeRequest(t *testing.T) {
+	first := &JSONRPCRequest{Version: "2.0", ID: NewID(1), Method: "add", Params: []int{1, 2}}
+	second := &JSONRPCRequest{Version: "2.0", ID: NewNotificationID(), Method: "log", Params: map[string]string{"msg": "<hi>"}}
+
+	t.Run("matches what the HTTP transport sends", func(t *testing.T) {
+		var body []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ = io.ReadAll(r.Body)
+			_, _ = w.Write([]byte(`[{"jsonrpc":"2.0","id":1,"result":3}]`))
+		}))
+		defer server.Close()
+
+		requests := []*JSONRPCRequest{first, second}
+		if _, err := NewHTTPTransport(server.URL).SendRequest(context.Background(), &SendRequestInput{Requests: requests, Batch: true}); err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+		encoded, err := EncodeRequest(requests, true)
+		if err != nil {
+			t.Fatalf("EncodeRequest error: %v", err)
+		}
+		if string(encoded) != string(body) {
+			t.Errorf("expected: %s, got: %s", body, encoded)
+		}
+	})
+
+	t.Run("single request", func(t *testing.T) {
+		encoded, err := EncodeRequest([]*JSONRPCRequest{first}, false)
+		if err != nil {
+			t.Fatalf("EncodeRequest error: %v", err)
+		}
+		expected := `{"jsonrpc":"2.0","id":1,"method":"add","params":[1,2]}`
+		if string(encoded) != expected {
+			t.Errorf("expected: %s, got: %s", expected, encoded)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var invalidErr *InvalidRequestError
+		if _, err := EncodeRequest(nil, true); !errors.As(err, &invalidErr) {
+			t.Errorf("expected error type: *InvalidRequestError, got: %T", err)
+		}
+		if _, err := EncodeRequest([]*JSONRPCRequest{first, second}, false); !errors.As(err, &invalidErr) {
+			t.Errorf("expected error type: *InvalidRequestError, got: %T", err)
+		}
+
+		_, err := EncodeRequest([]*JSONRPCRequest{{Method: "bad", Params: func() {}}}, false)
+		var marshalErr *MarshalError
+		if !errors.As(err, &marshalErr) || marshalErr.Method != "bad" {
+			t.Errorf("expected a MarshalError for bad, got: %v", err)
+		}
+	})
+}
+
+func TestDecodeResponse(t *testing.T) {
+	t.Run("single", func(t *testing.T) {
+		responses, err := DecodeResponse([]byte(`{"jsonrpc":"2.0","id":1,"result":3}`), false)
+		if err != nil {
+			t.Fatalf("DecodeResponse error: %v", err)
+		}
+		if len(responses) != 1 || responses[0].ID.String() != "1" || string(responses[0].Result) != "3" {
+			t.Errorf("unexpected responses: %+v", responses)
+		}
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		responses, err := DecodeResponse([]byte(`[{"jsonrpc":"2.0","id":1,"result":3},{"jsonrpc":"2.0","id":2,"error":{"code":-32601,"message":"nope"}}]`), true)
+		if err != nil {
+			t.Fatalf("DecodeResponse error: %v", err)
+		}
+		if len(responses) != 2 || responses[1].Error == nil || responses[1].Error.Code != -32601 {
+			t.Errorf("unexpected responses: %+v", responses)
+		}
+	})
+
+	t.Run("batch answered by an object", func(t *testing.T) {
+		responses, err := DecodeResponse([]byte(`{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"bad batch"}}`), true)
+		if err != nil {
+			t.Fatalf("DecodeResponse error: %v", err)
+		}
+		if len(responses) != 1 || responses[0].Error == nil {
+			t.Errorf("unexpected responses: %+v", responses)
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := DecodeResponse([]byte(`{"jsonrpc":`), false)
+		var unmarshalErr *UnmarshalError
+		if !errors.As(err, &unmarshalErr) {
+			t.Errorf("expected error type: *UnmarshalError, got: %T", err)
+		}
+	})
+}
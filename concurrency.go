@@ -0,0 +1,99 @@
+package jsonrpc_client
+
+import (
+	"context"
+)
+
+// WithMaxConcurrency limits the number of requests a client has in flight at once. A call
+// that would exceed the limit blocks until a slot is released or its context is done.
+// A batch takes a single slot unless WithBatchSlotsPerRequest is also set.
+func WithMaxConcurrency(n int) ClientOption {
+	return func(c *Client) {
+		if n <= 0 {
+			c.limiter = nil
+			return
+		}
+		c.limiter = newSemaphore(n)
+	}
+}
+
+// WithBatchSlotsPerRequest makes a batch take one WithMaxConcurrency slot per request it
+// contains, up to the limit itself, instead of a single slot
+func WithBatchSlotsPerRequest() ClientOption {
+	return func(c *Client) {
+		c.batchSlotsPerRequest = true
+	}
+}
+
+// send hands input to the transport, holding concurrency slots for the duration of the call,
+// adding the WithDeadlineParam hint and logging the requests
+func (c *Client) send(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+	if c.limiter != nil {
+		slots := 1
+		if input.Batch && c.batchSlotsPerRequest {
+			slots = len(input.Requests)
+		}
+		slots, err := c.limiter.acquire(ctx, slots)
+		if err != nil {
+			return nil, &InvokeError{Method: input.Requests[0].Method, Err: err}
+		}
+		defer c.limiter.release(slots)
+	}
+	// Added after waiting for a slot, so that the hint reflects the time actually left
+	if c.deadlineParam != "" {
+		if err := c.addDeadlineParam(ctx, input.Requests); err != nil {
+			return nil, err
+		}
+	}
+	if c.logger == nil {
+		return c.transport.SendRequest(ctx, input)
+	}
+	start := c.clock.Now()
+	output, err := c.transport.SendRequest(ctx, input)
+	c.logRequests(input, c.clock.Now().Sub(start), err)
+	return output, err
+}
+
+// semaphore is a counting semaphore whose acquisition honors context cancellation
+type semaphore struct {
+	slots chan struct{}
+	// turn is held while a multi-slot acquisition is in progress, so that two callers
+	// never hold part of the slots each while waiting for the rest
+	turn chan struct{}
+}
+
+func newSemaphore(n int) *semaphore {
+	return &semaphore{
+		slots: make(chan struct{}, n),
+		turn:  make(chan struct{}, 1),
+	}
+}
+
+// acquire takes n slots, capped at the semaphore size, and returns how many were taken
+func (s *semaphore) acquire(ctx context.Context, n int) (int, error) {
+	n = min(max(n, 1), cap(s.slots))
+
+	select {
+	case s.turn <- struct{}{}:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+	defer func() { <-s.turn }()
+
+	for i := 0; i < n; i++ {
+		select {
+		case s.slots <- struct{}{}:
+		case <-ctx.Done():
+			s.release(i)
+			return 0, ctx.Err()
+		}
+	}
+	return n, nil
+}
+
+// release returns n slots
+func (s *semaphore) release(n int) {
+	for i := 0; i < n; i++ {
+		<-s.slots
+	}
+}
@@ -0,0 +1,158 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingTransport answers requests only when release is closed, tracking how many are in flight
+type blockingTransport struct {
+	release  chan struct{}
+	inFlight atomic.Int32
+	peak     atomic.Int32
+	started  chan struct{}
+}
+
+func newBlockingTransport() *blockingTransport {
+	return &blockingTransport{
+		release: make(chan struct{}),
+		started: make(chan struct{}, 100),
+	}
+}
+
+func (b *blockingTransport) SendRequest(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+	n := b.inFlight.Add(1)
+	defer b.inFlight.Add(-1)
+	for {
+		peak := b.peak.Load()
+		if n <= peak || b.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	b.started <- struct{}{}
+	<-b.release
+
+	responses := make([]*JSONRPCResponse, len(input.Requests))
+	for i, req := range input.Requests {
+		responses[i] = &JSONRPCResponse{Version: "2.0", ID: req.ID, Result: json.RawMessage(`"ok"`)}
+	}
+	return &SendRequestOutput{Responses: responses}, nil
+}
+
+func TestWithMaxConcurrency(t *testing.T) {
+	t.Run("cap is respected", func(t *testing.T) {
+		transport := newBlockingTransport()
+		client := NewClient(transport, WithMaxConcurrency(3))
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 10)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- client.Invoke(context.Background(), &Invoke[[]int, string]{Name: "test", Request: []int{1}})
+			}()
+		}
+
+		// Wait until the limit is reached, then give extra callers a chance to exceed it
+		for i := 0; i < 3; i++ {
+			<-transport.started
+		}
+		time.Sleep(20 * time.Millisecond)
+		if got := transport.inFlight.Load(); got != 3 {
+			t.Errorf("expected 3 requests in flight, got: %d", got)
+		}
+
+		close(transport.release)
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			if err != nil {
+				t.Errorf("Invoke error: %v", err)
+			}
+		}
+		if peak := transport.peak.Load(); peak != 3 {
+			t.Errorf("expected peak concurrency: 3, got: %d", peak)
+		}
+	})
+
+	t.Run("waiting honors context", func(t *testing.T) {
+		transport := newBlockingTransport()
+		defer close(transport.release)
+		client := NewClient(transport, WithMaxConcurrency(1))
+
+		go client.Invoke(context.Background(), &Invoke[[]int, string]{Name: "first", Request: []int{1}})
+		<-transport.started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := client.Invoke(ctx, &Invoke[[]int, string]{Name: "second", Request: []int{1}})
+
+		var invokeErr *InvokeError
+		if !errors.As(err, &invokeErr) {
+			t.Fatalf("expected error type: *InvokeError, got: %T", err)
+		}
+		if invokeErr.Method != "second" {
+			t.Errorf("expected method: second, got: %s", invokeErr.Method)
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected wrapped context.DeadlineExceeded, got: %v", err)
+		}
+	})
+
+	t.Run("batch takes one slot", func(t *testing.T) {
+		transport := newBlockingTransport()
+		client := NewClient(transport, WithMaxConcurrency(2))
+
+		batch := []MethodCaller{
+			&Invoke[[]int, string]{Name: "a", Request: []int{1}},
+			&Invoke[[]int, string]{Name: "b", Request: []int{1}},
+		}
+		done := make(chan error, 2)
+		go func() { done <- client.InvokeBatch(context.Background(), batch) }()
+		go func() {
+			done <- client.Invoke(context.Background(), &Invoke[[]int, string]{Name: "c", Request: []int{1}})
+		}()
+
+		<-transport.started
+		<-transport.started
+		close(transport.release)
+		for i := 0; i < 2; i++ {
+			if err := <-done; err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+	})
+
+	t.Run("batch slots per request", func(t *testing.T) {
+		transport := newBlockingTransport()
+		client := NewClient(transport, WithMaxConcurrency(2), WithBatchSlotsPerRequest())
+
+		batch := []MethodCaller{
+			&Invoke[[]int, string]{Name: "a", Request: []int{1}},
+			&Invoke[[]int, string]{Name: "b", Request: []int{1}},
+			&Invoke[[]int, string]{Name: "c", Request: []int{1}},
+		}
+		done := make(chan error, 1)
+		go func() { done <- client.InvokeBatch(context.Background(), batch) }()
+		<-transport.started
+
+		// The batch holds both slots, so a single call cannot start
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := client.Invoke(ctx, &Invoke[[]int, string]{Name: "d", Request: []int{1}})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected wrapped context.DeadlineExceeded, got: %v", err)
+		}
+
+		close(transport.release)
+		if err := <-done; err != nil {
+			t.Errorf("InvokeBatch error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// endpointKey is the context key for a per-call endpoint override
+type endpointKey struct{}
+
+// WithEndpoint returns a context that makes HTTPTransport send the call to endpoint
+// instead of its configured base URL
+func WithEndpoint(ctx context.Context, endpoint string) context.Context {
+	return context.WithValue(ctx, endpointKey{}, endpoint)
+}
+
+// EndpointFromContext returns the endpoint override set by WithEndpoint, if any
+func EndpointFromContext(ctx context.Context) (string, bool) {
+	endpoint, ok := ctx.Value(endpointKey{}).(string)
+	return endpoint, ok
+}
+
+// validateEndpoint checks that endpoint is an absolute URL with a scheme and host
+func validateEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("endpoint %q must be an absolute URL", endpoint)
+	}
+	return nil
+}
+
+// WithBaseContext sets a context whose values, e.g. a parent span or an auth carrier, are
+// visible to every request the transport sends, so they need not be put on each call's
+// context. Only values are taken from base: its deadline and cancellation are ignored, and
+// those of the per-call context apply. When both contexts hold a value for the same key, the
+// per-call one wins.
+func WithBaseContext(base context.Context) HTTPTransportOption {
+	return func(t *HTTPTransport) {
+		t.baseCtx = base
+	}
+}
+
+// valuesContext is a context that falls back to the values of base for keys ctx lacks
+type valuesContext struct {
+	context.Context
+	base context.Context
+}
+
+func (c valuesContext) Value(key any) any {
+	if v := c.Context.Value(key); v != nil {
+		return v
+	}
+	return c.base.Value(key)
+}
@@ -0,0 +1,124 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithEndpoint(t *testing.T) {
+	newServer := func(result string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"` + result + `"}`))
+		}))
+	}
+	primary := newServer("primary")
+	defer primary.Close()
+	shard := newServer("shard")
+	defer shard.Close()
+
+	client := NewClient(NewHTTPTransport(primary.URL))
+
+	t.Run("without override", func(t *testing.T) {
+		invoke := &Invoke[map[string]string, string]{Name: "test.method", Request: map[string]string{}}
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if invoke.Response != "primary" {
+			t.Errorf("expected result: primary, got: %s", invoke.Response)
+		}
+	})
+
+	t.Run("with override", func(t *testing.T) {
+		ctx := WithEndpoint(context.Background(), shard.URL)
+		invoke := &Invoke[map[string]string, string]{Name: "test.method", Request: map[string]string{}}
+		if err := client.Invoke(ctx, invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if invoke.Response != "shard" {
+			t.Errorf("expected result: shard, got: %s", invoke.Response)
+		}
+	})
+
+	t.Run("invalid override", func(t *testing.T) {
+		for _, endpoint := range []string{"not a url", "/relative/path", "http://%zz"} {
+			ctx := WithEndpoint(context.Background(), endpoint)
+			invoke := &Invoke[map[string]string, string]{Name: "test.method", Request: map[string]string{}}
+			err := client.Invoke(ctx, invoke)
+
+			var invalidErr *InvalidRequestError
+			if !errors.As(err, &invalidErr) {
+				t.Errorf("expected error type: *InvalidRequestError for %q, got: %T", endpoint, err)
+			}
+		}
+	})
+
+	t.Run("EndpointFromContext", func(t *testing.T) {
+		if _, ok := EndpointFromContext(context.Background()); ok {
+			t.Error("expected no endpoint in empty context")
+		}
+		endpoint, ok := EndpointFromContext(WithEndpoint(context.Background(), "http://example.com"))
+		if !ok || endpoint != "http://example.com" {
+			t.Errorf("expected endpoint: http://example.com, got: %s", endpoint)
+		}
+	})
+}
+
+func TestWithBaseContext(t *testing.T) {
+	type tenantKey struct{}
+
+	release := make(chan struct{})
+	var tenant string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant = r.Header.Get("X-Tenant")
+		if r.Header.Get("X-Block") != "" {
+			<-release
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"ok"}`))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	fromContext := func(ctx context.Context) (string, bool) {
+		v, ok := ctx.Value(tenantKey{}).(string)
+		return v, ok
+	}
+	base, cancelBase := context.WithCancel(context.WithValue(context.Background(), tenantKey{}, "base"))
+	// The base context being cancelled must not affect calls
+	cancelBase()
+	transport := NewHTTPTransport(server.URL, WithBaseContext(base), WithHeaderFromContext("X-Tenant", fromContext))
+	request := &JSONRPCRequest{Version: "2.0", ID: NewID(1), Method: "test"}
+
+	t.Run("base values are visible", func(t *testing.T) {
+		if _, err := transport.SendRequest(context.Background(), &SendRequestInput{Requests: []*JSONRPCRequest{request}}); err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+		if tenant != "base" {
+			t.Errorf("expected tenant: base, got: %s", tenant)
+		}
+	})
+
+	t.Run("per-call values win", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), tenantKey{}, "call")
+		if _, err := transport.SendRequest(ctx, &SendRequestInput{Requests: []*JSONRPCRequest{request}}); err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+		if tenant != "call" {
+			t.Errorf("expected tenant: call, got: %s", tenant)
+		}
+	})
+
+	t.Run("per-call cancellation works", func(t *testing.T) {
+		blocking := NewHTTPTransport(server.URL, WithBaseContext(context.Background()), WithHTTPHeaders(map[string]string{"X-Block": "1"}))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := blocking.SendRequest(ctx, &SendRequestInput{Requests: []*JSONRPCRequest{request}})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected the call to be cancelled, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// remainingMillis returns the time left until the deadline of ctx in whole milliseconds,
+// or false if ctx has no deadline
+func remainingMillis(ctx context.Context) (int64, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return max(time.Until(deadline).Milliseconds(), 0), true
+}
+
+// WithDeadlineHeader makes the transport send the time left until the deadline of the call's
+// context, in milliseconds, in the header name, e.g. "X-Deadline: 1500", so the server can
+// abandon work the client has already given up on. Calls without a deadline carry no header.
+func WithDeadlineHeader(name string) HTTPTransportOption {
+	return WithHeaderFromContext(name, func(ctx context.Context) (string, bool) {
+		ms, ok := remainingMillis(ctx)
+		return strconv.FormatInt(ms, 10), ok
+	})
+}
+
+// WithDeadlineParam makes the client add the time left until the deadline of the call, in
+// milliseconds, to the params of each request as the member name, e.g. "timeout": 1500.
+// The deadline includes WithTimeout and WithMethodTimeout. Requests without params get an
+// object holding just the hint; requests with positional (array) params are sent unchanged,
+// as are all requests of calls without a deadline. The hint changes from call to call, so a
+// CacheTransport with DefaultCacheKey will not find earlier responses.
+func WithDeadlineParam(name string) ClientOption {
+	return func(c *Client) {
+		c.deadlineParam = name
+	}
+}
+
+// addDeadlineParam adds the WithDeadlineParam member to the params of requests
+func (c *Client) addDeadlineParam(ctx context.Context, requests []*JSONRPCRequest) error {
+	ms, ok := remainingMillis(ctx)
+	if !ok {
+		return nil
+	}
+	for _, request := range requests {
+		params, err := withParam(request.Params, c.deadlineParam, ms)
+		if err != nil {
+			return &MarshalError{Method: request.Method, Err: err}
+		}
+		request.Params = params
+	}
+	return nil
+}
+
+// withParam returns params with the member name set to value, or params unchanged if they
+// are not a JSON object
+func withParam(params any, name string, value any) (any, error) {
+	members := map[string]json.RawMessage{}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		if json.Unmarshal(data, &members) != nil || members == nil {
+			return params, nil
+		}
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	members[name] = encoded
+	data, err := json.Marshal(members)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(data), nil
+}
@@ -0,0 +1,115 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestHTTPTransportDeadlineHeader(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"ok"}`))
+	}))
+	defer server.Close()
+
+	transport := NewHTTPTransport(server.URL, WithDeadlineHeader("X-Deadline"))
+	input := &SendRequestInput{Requests: []*JSONRPCRequest{{Version: "2.0", ID: NewID(1), Method: "test"}}}
+
+	t.Run("with deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if _, err := transport.SendRequest(ctx, input); err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+		ms, err := strconv.Atoi(header.Get("X-Deadline"))
+		if err != nil || ms <= 1000 || ms > 2000 {
+			t.Errorf("expected about 2000ms, got: %q", header.Get("X-Deadline"))
+		}
+	})
+
+	t.Run("without deadline", func(t *testing.T) {
+		if _, err := transport.SendRequest(context.Background(), input); err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+		if _, ok := header["X-Deadline"]; ok {
+			t.Errorf("expected no X-Deadline header, got: %s", header.Get("X-Deadline"))
+		}
+	})
+}
+
+func TestWithDeadlineParam(t *testing.T) {
+	var sent []byte
+	transport := &MockTransport{
+		SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			sent, _ = json.Marshal(input.Requests[0].Params)
+			return &SendRequestOutput{Responses: []*JSONRPCResponse{
+				{Version: "2.0", ID: input.Requests[0].ID, Result: json.RawMessage(`"ok"`)},
+			}}, nil
+		},
+	}
+	type query struct {
+		Name string `json:"name"`
+	}
+	timeoutOf := func(t *testing.T) (int, bool) {
+		t.Helper()
+		var params map[string]any
+		if err := json.Unmarshal(sent, &params); err != nil {
+			return 0, false
+		}
+		ms, ok := params["timeout"].(float64)
+		return int(ms), ok
+	}
+
+	t.Run("object params", func(t *testing.T) {
+		client := NewClient(transport, WithDeadlineParam("timeout"), WithTimeout(3*time.Second))
+		if err := client.Invoke(context.Background(), &Invoke[query, string]{Name: "test", Request: query{Name: "a"}}); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		ms, ok := timeoutOf(t)
+		if !ok || ms <= 2000 || ms > 3000 {
+			t.Errorf("expected a timeout of about 3000ms, got: %s", sent)
+		}
+		var params query
+		if err := json.Unmarshal(sent, &params); err != nil || params.Name != "a" {
+			t.Errorf("expected the original params to be kept, got: %s", sent)
+		}
+	})
+
+	t.Run("no params", func(t *testing.T) {
+		client := NewClient(transport, WithDeadlineParam("timeout"))
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := client.Invoke(ctx, &Invoke[any, string]{Name: "test"}); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if _, ok := timeoutOf(t); !ok {
+			t.Errorf("expected params with a timeout, got: %s", sent)
+		}
+	})
+
+	t.Run("positional params are unchanged", func(t *testing.T) {
+		client := NewClient(transport, WithDeadlineParam("timeout"), WithTimeout(time.Second))
+		if err := client.Invoke(context.Background(), &Invoke[[]int, string]{Name: "test", Request: []int{1, 2}}); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if string(sent) != "[1,2]" {
+			t.Errorf("expected params: [1,2], got: %s", sent)
+		}
+	})
+
+	t.Run("without deadline", func(t *testing.T) {
+		client := NewClient(transport, WithDeadlineParam("timeout"))
+		if err := client.Invoke(context.Background(), &Invoke[query, string]{Name: "test", Request: query{Name: "a"}}); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if string(sent) != `{"name":"a"}` {
+			t.Errorf("expected params without a timeout, got: %s", sent)
+		}
+	})
+}
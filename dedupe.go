@@ -0,0 +1,62 @@
+package jsonrpc_client
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// DedupeMode selects how InvokeBatch handles calls with the same method and params
+type DedupeMode int
+
+const (
+	// DedupeOff sends duplicate calls as-is (default)
+	DedupeOff DedupeMode = iota
+	// DedupeError rejects a batch containing duplicate calls with InvalidRequestError
+	DedupeError
+	// DedupeCollapse sends each distinct call once and decodes its response into every duplicate
+	DedupeCollapse
+)
+
+// WithDedupeBatch enables detection of calls within a batch that share the same method
+// and identical params. Notifications are never deduplicated.
+func WithDedupeBatch(mode DedupeMode) ClientOption {
+	return func(c *Client) {
+		c.dedupe = mode
+	}
+}
+
+// dedupeBatch returns the requests to send and, for each request, the index of the request
+// whose response answers it. With deduplication off, requests is returned unchanged.
+func (c *Client) dedupeBatch(requests []*JSONRPCRequest) ([]*JSONRPCRequest, []int, error) {
+	primary := make([]int, len(requests))
+	for i := range primary {
+		primary[i] = i
+	}
+	if c.dedupe == DedupeOff {
+		return requests, primary, nil
+	}
+
+	seen := make(map[string]int, len(requests))
+	sent := make([]*JSONRPCRequest, 0, len(requests))
+	for i, request := range requests {
+		if request.ID.IsNotification() {
+			sent = append(sent, request)
+			continue
+		}
+		params, err := json.Marshal(request.Params)
+		if err != nil {
+			return nil, nil, &MarshalError{Method: request.Method, Err: err}
+		}
+		key := request.Method + "\x00" + string(params)
+		if j, ok := seen[key]; ok {
+			if c.dedupe == DedupeError {
+				return nil, nil, &InvalidRequestError{Message: fmt.Sprintf("requests %d and %d are duplicate calls to %q", j, i, request.Method)}
+			}
+			primary[i] = j
+			continue
+		}
+		seen[key] = i
+		sent = append(sent, request)
+	}
+	return sent, primary, nil
+}
@@ -0,0 +1,89 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWithDedupeBatch(t *testing.T) {
+	var sent []*JSONRPCRequest
+	transport := &MockTransport{
+		SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			sent = input.Requests
+			output := &SendRequestOutput{}
+			for _, request := range input.Requests {
+				if request.ID.IsNotification() {
+					continue
+				}
+				params, _ := json.Marshal(request.Params)
+				output.Responses = append(output.Responses, &JSONRPCResponse{ID: request.ID, Result: params})
+			}
+			return output, nil
+		},
+	}
+
+	newBatch := func() ([]*Invoke[[]int, []int], []MethodCaller) {
+		invokes := []*Invoke[[]int, []int]{
+			{Name: "echo", Request: []int{1}},
+			{Name: "echo", Request: []int{2}},
+			{Name: "echo", Request: []int{1}},
+			{Name: "other", Request: []int{1}},
+		}
+		callers := make([]MethodCaller, 0, len(invokes)+2)
+		for _, invoke := range invokes {
+			callers = append(callers, invoke)
+		}
+		// Identical notifications are never deduplicated
+		callers = append(callers,
+			AsNotification(&Invoke[[]int, Omit]{Name: "echo", Request: []int{1}}),
+			AsNotification(&Invoke[[]int, Omit]{Name: "echo", Request: []int{1}}),
+		)
+		return invokes, callers
+	}
+
+	t.Run("off by default", func(t *testing.T) {
+		client := NewClient(transport)
+		_, callers := newBatch()
+		if err := client.InvokeBatch(context.Background(), callers); err != nil {
+			t.Fatalf("InvokeBatch error: %v", err)
+		}
+		if len(sent) != 6 {
+			t.Errorf("expected 6 requests sent, got: %d", len(sent))
+		}
+	})
+
+	t.Run("DedupeError", func(t *testing.T) {
+		client := NewClient(transport, WithDedupeBatch(DedupeError))
+		_, callers := newBatch()
+		err := client.InvokeBatch(context.Background(), callers)
+
+		var invalidErr *InvalidRequestError
+		if !errors.As(err, &invalidErr) {
+			t.Fatalf("expected error type: *InvalidRequestError, got: %T", err)
+		}
+		if !strings.Contains(invalidErr.Message, "requests 0 and 2") {
+			t.Errorf("expected duplicate indices in message, got: %s", invalidErr.Message)
+		}
+	})
+
+	t.Run("DedupeCollapse", func(t *testing.T) {
+		client := NewClient(transport, WithDedupeBatch(DedupeCollapse))
+		invokes, callers := newBatch()
+		if err := client.InvokeBatch(context.Background(), callers); err != nil {
+			t.Fatalf("InvokeBatch error: %v", err)
+		}
+		if len(sent) != 5 {
+			t.Errorf("expected 5 requests sent, got: %d", len(sent))
+		}
+
+		expected := [][]int{{1}, {2}, {1}, {1}}
+		for i, invoke := range invokes {
+			if len(invoke.Response) != 1 || invoke.Response[0] != expected[i][0] {
+				t.Errorf("expected response %d: %v, got: %v", i, expected[i], invoke.Response)
+			}
+		}
+	})
+}
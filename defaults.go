@@ -0,0 +1,58 @@
+package jsonrpc_client
+
+import "time"
+
+// Defaults holds client settings shared by many clients, so they can be configured in one
+// place and passed to NewClientWithDefaults. Zero fields leave the setting at its default.
+type Defaults struct {
+	// Timeout is applied as WithTimeout
+	Timeout time.Duration
+	// MethodTimeouts is applied as WithMethodTimeout
+	MethodTimeouts map[string]time.Duration
+	// Version is applied as WithVersion
+	Version string
+	// EmptyParams is applied as WithEmptyParams
+	EmptyParams ParamsPolicy
+	// MaxConcurrency is applied as WithMaxConcurrency; each client gets its own limit
+	MaxConcurrency int
+	// Validation enables WithValidation
+	Validation bool
+	// UseNumber enables WithUseNumber
+	UseNumber bool
+	// Options are applied after the fields above, for settings without a field. They are
+	// shared by every client built from these defaults.
+	Options []ClientOption
+}
+
+// options returns the client options equivalent to d
+func (d Defaults) options() []ClientOption {
+	var opts []ClientOption
+	if d.Timeout > 0 {
+		opts = append(opts, WithTimeout(d.Timeout))
+	}
+	if d.MethodTimeouts != nil {
+		opts = append(opts, WithMethodTimeout(d.MethodTimeouts))
+	}
+	if d.Version != "" {
+		opts = append(opts, WithVersion(d.Version))
+	}
+	if d.EmptyParams != ParamsOmit {
+		opts = append(opts, WithEmptyParams(d.EmptyParams))
+	}
+	if d.MaxConcurrency > 0 {
+		opts = append(opts, WithMaxConcurrency(d.MaxConcurrency))
+	}
+	if d.Validation {
+		opts = append(opts, WithValidation())
+	}
+	if d.UseNumber {
+		opts = append(opts, WithUseNumber())
+	}
+	return append(opts, d.Options...)
+}
+
+// NewClientWithDefaults creates a client configured with defaults, then with opts, so a
+// per-client option overrides the corresponding default
+func NewClientWithDefaults(transport Transport, defaults Defaults, opts ...ClientOption) *Client {
+	return NewClient(transport, append(defaults.options(), opts...)...)
+}
@@ -0,0 +1,74 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewClientWithDefaults(t *testing.T) {
+	var sent []byte
+	var deadline time.Duration
+	transport := &MockTransport{
+		SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			sent, _ = json.Marshal(input.Requests[0])
+			if d, ok := ctx.Deadline(); ok {
+				deadline = time.Until(d)
+			}
+			return &SendRequestOutput{Responses: []*JSONRPCResponse{
+				{Version: "2.0", ID: input.Requests[0].ID, Result: json.RawMessage(`"ok"`)},
+			}}, nil
+		},
+	}
+	defaults := Defaults{
+		Timeout:     time.Hour,
+		EmptyParams: ParamsEmptyArray,
+		Options:     []ClientOption{WithSequenceIDGeneratorStart(100)},
+	}
+
+	t.Run("defaults are applied", func(t *testing.T) {
+		client := NewClientWithDefaults(transport, defaults)
+		if err := client.Invoke(context.Background(), &Invoke[any, string]{Name: "test"}); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+
+		expected := `{"jsonrpc":"2.0","id":100,"method":"test","params":[]}`
+		if string(sent) != expected {
+			t.Errorf("expected request: %s, got: %s", expected, sent)
+		}
+		if deadline <= 59*time.Minute {
+			t.Errorf("expected a deadline of about an hour, got: %s", deadline)
+		}
+	})
+
+	t.Run("options override defaults", func(t *testing.T) {
+		client := NewClientWithDefaults(transport, defaults,
+			WithTimeout(time.Minute),
+			WithEmptyParams(ParamsOmit),
+			WithSequenceIDGeneratorStart(7),
+		)
+		if err := client.Invoke(context.Background(), &Invoke[any, string]{Name: "test"}); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+
+		expected := `{"jsonrpc":"2.0","id":7,"method":"test"}`
+		if string(sent) != expected {
+			t.Errorf("expected request: %s, got: %s", expected, sent)
+		}
+		if deadline > time.Minute {
+			t.Errorf("expected a deadline within a minute, got: %s", deadline)
+		}
+	})
+
+	t.Run("zero defaults", func(t *testing.T) {
+		client := NewClientWithDefaults(transport, Defaults{})
+		if err := client.Invoke(context.Background(), &Invoke[any, string]{Name: "test"}); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if strings.Contains(string(sent), "params") {
+			t.Errorf("expected params to be omitted, got: %s", sent)
+		}
+	})
+}
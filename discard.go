@@ -0,0 +1,41 @@
+package jsonrpc_client
+
+import "context"
+
+// DiscardTransport answers requests in memory without any I/O. Requests are still encoded
+// to JSON and then discarded, so benchmarks include the serialization cost but not the
+// network. It is also handy for deterministic tests and offline modes.
+type DiscardTransport struct {
+	responder func(*JSONRPCRequest) *JSONRPCResponse
+}
+
+// NewDiscardTransport creates a DiscardTransport. responder builds the response to each
+// request; returning nil leaves the request unanswered. A nil responder answers nothing,
+// so every non-notification call fails with EmptyResponseError.
+func NewDiscardTransport(responder func(*JSONRPCRequest) *JSONRPCResponse) *DiscardTransport {
+	return &DiscardTransport{responder: responder}
+}
+
+// SendRequest encodes the request, discards it and returns the responder's responses
+func (t *DiscardTransport) SendRequest(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+	if len(input.Requests) == 0 {
+		return nil, &InvalidRequestError{Message: "no request provided"}
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := encodeRequests(buf, input.Requests, input.Batch, encodeJSON); err != nil {
+		return nil, &MarshalError{Method: input.Requests[0].Method, Err: err}
+	}
+
+	output := &SendRequestOutput{}
+	if t.responder == nil {
+		return output, nil
+	}
+	for _, request := range input.Requests {
+		if resp := t.responder(request); resp != nil {
+			output.Responses = append(output.Responses, resp)
+		}
+	}
+	return output, nil
+}
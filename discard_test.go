@@ -0,0 +1,86 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// echoResponder answers every request with its method name
+func echoResponder(req *JSONRPCRequest) *JSONRPCResponse {
+	if req.ID.IsNotification() {
+		return nil
+	}
+	result, _ := json.Marshal(req.Method)
+	return &JSONRPCResponse{Version: "2.0", ID: req.ID, Result: result}
+}
+
+func TestDiscardTransport(t *testing.T) {
+	t.Run("responder", func(t *testing.T) {
+		client := NewClient(NewDiscardTransport(echoResponder))
+
+		invoke := &Invoke[[]int, string]{Name: "echo", Request: []int{1}}
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if invoke.Response != "echo" {
+			t.Errorf("expected response: echo, got: %s", invoke.Response)
+		}
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		client := NewClient(NewDiscardTransport(echoResponder))
+
+		first := &Invoke[[]int, string]{Name: "first", Request: []int{1}}
+		second := &Invoke[[]int, string]{Name: "second", Request: []int{2}}
+		notify := AsNotification(&Invoke[[]int, string]{Name: "notify", Request: []int{3}})
+		if err := client.InvokeBatch(context.Background(), []MethodCaller{first, second, notify}); err != nil {
+			t.Fatalf("InvokeBatch error: %v", err)
+		}
+		if first.Response != "first" || second.Response != "second" {
+			t.Errorf("expected responses: first and second, got: %s and %s", first.Response, second.Response)
+		}
+	})
+
+	t.Run("nil responder", func(t *testing.T) {
+		client := NewClient(NewDiscardTransport(nil))
+
+		err := client.Invoke(context.Background(), &Invoke[[]int, string]{Name: "echo", Request: []int{1}})
+
+		var emptyErr *EmptyResponseError
+		if !errors.As(err, &emptyErr) {
+			t.Fatalf("expected error type: *EmptyResponseError, got: %T", err)
+		}
+	})
+
+	t.Run("encode error", func(t *testing.T) {
+		client := NewClient(NewDiscardTransport(echoResponder))
+
+		err := client.Invoke(context.Background(), &Invoke[func(), string]{Name: "echo", Request: func() {}})
+
+		var marshalErr *MarshalError
+		if !errors.As(err, &marshalErr) {
+			t.Fatalf("expected error type: *MarshalError, got: %T", err)
+		}
+	})
+}
+
+func BenchmarkClientInvokeDiscard(b *testing.B) {
+	type params struct {
+		Name  string   `json:"name"`
+		Count int      `json:"count"`
+		Tags  []string `json:"tags"`
+	}
+	client := NewClient(NewDiscardTransport(echoResponder))
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		invoke := &Invoke[params, string]{Name: "echo", Request: params{Name: "test", Count: i, Tags: []string{"a", "b"}}}
+		if err := client.Invoke(ctx, invoke); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
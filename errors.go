@@ -1,6 +1,7 @@
 package jsonrpc_client
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 )
@@ -14,12 +15,17 @@ type Error interface {
 // InvokeError represents an error that occurs during method invocation
 type InvokeError struct {
 	Method string
+	ID     *IDValue
 	Err    error
+
+	// permanent marks a failure that happened before the request was sent, e.g. in the
+	// request mutator, so that sending it again fails the same way (see DefaultRetryable)
+	permanent bool
 }
 
 // Error returns a string representation of the invoke error
 func (e *InvokeError) Error() string {
-	return fmt.Sprintf("rpc: invoke error [%s]: %v", e.Method, e.Err)
+	return fmt.Sprintf("rpc: invoke error [%s]: %v", methodLabel(e.Method, e.ID), e.Err)
 }
 
 // IsRPCError implements the Error interface
@@ -27,6 +33,13 @@ func (e *InvokeError) IsRPCError() bool {
 	return true
 }
 
+// setRequestID records the ID of the request that failed
+func (e *InvokeError) setRequestID(id *IDValue) {
+	if e.ID == nil {
+		e.ID = id
+	}
+}
+
 // Unwrap returns the underlying error
 func (e *InvokeError) Unwrap() error {
 	return e.Err
@@ -35,12 +48,13 @@ func (e *InvokeError) Unwrap() error {
 // FunctionError represents an error that occurs inside a function
 type FunctionError struct {
 	Method  string
+	ID      *IDValue
 	Message string
 }
 
 // Error returns a string representation of the function error
 func (e *FunctionError) Error() string {
-	return fmt.Sprintf("rpc: function error [%s]: %s", e.Method, e.Message)
+	return fmt.Sprintf("rpc: function error [%s]: %s", methodLabel(e.Method, e.ID), e.Message)
 }
 
 // IsRPCError implements the Error interface
@@ -48,15 +62,23 @@ func (e *FunctionError) IsRPCError() bool {
 	return true
 }
 
+// setRequestID records the ID of the request that failed
+func (e *FunctionError) setRequestID(id *IDValue) {
+	if e.ID == nil {
+		e.ID = id
+	}
+}
+
 // StatusCodeError represents an error with a non-200 status code
 type StatusCodeError struct {
 	Method     string
+	ID         *IDValue
 	StatusCode int
 }
 
 // Error returns a string representation of the status code error
 func (e *StatusCodeError) Error() string {
-	return fmt.Sprintf("rpc: non-200 status code [%s]: %d", e.Method, e.StatusCode)
+	return fmt.Sprintf("rpc: non-200 status code [%s]: %d", methodLabel(e.Method, e.ID), e.StatusCode)
 }
 
 // IsRPCError implements the Error interface
@@ -64,14 +86,22 @@ func (e *StatusCodeError) IsRPCError() bool {
 	return true
 }
 
+// setRequestID records the ID of the request that failed
+func (e *StatusCodeError) setRequestID(id *IDValue) {
+	if e.ID == nil {
+		e.ID = id
+	}
+}
+
 // EmptyPayloadError represents an error when the payload is empty
 type EmptyPayloadError struct {
 	Method string
+	ID     *IDValue
 }
 
 // Error returns a string representation of the empty payload error
 func (e *EmptyPayloadError) Error() string {
-	return fmt.Sprintf("rpc: empty payload [%s]", e.Method)
+	return fmt.Sprintf("rpc: empty payload [%s]", methodLabel(e.Method, e.ID))
 }
 
 // IsRPCError implements the Error interface
@@ -79,15 +109,23 @@ func (e *EmptyPayloadError) IsRPCError() bool {
 	return true
 }
 
+// setRequestID records the ID of the request that failed
+func (e *EmptyPayloadError) setRequestID(id *IDValue) {
+	if e.ID == nil {
+		e.ID = id
+	}
+}
+
 // UnmarshalError represents an error during JSON deserialization
 type UnmarshalError struct {
 	Method string
+	ID     *IDValue
 	Err    error
 }
 
 // Error returns a string representation of the unmarshal error
 func (e *UnmarshalError) Error() string {
-	return fmt.Sprintf("rpc: failed to unmarshal response [%s]: %v", e.Method, e.Err)
+	return fmt.Sprintf("rpc: failed to unmarshal response [%s]: %v", methodLabel(e.Method, e.ID), e.Err)
 }
 
 // IsRPCError implements the Error interface
@@ -95,6 +133,13 @@ func (e *UnmarshalError) IsRPCError() bool {
 	return true
 }
 
+// setRequestID records the ID of the request that failed
+func (e *UnmarshalError) setRequestID(id *IDValue) {
+	if e.ID == nil {
+		e.ID = id
+	}
+}
+
 // Unwrap returns the underlying error
 func (e *UnmarshalError) Unwrap() error {
 	return e.Err
@@ -103,11 +148,12 @@ func (e *UnmarshalError) Unwrap() error {
 // EmptyResultError represents an error when the result is empty
 type EmptyResultError struct {
 	Method string
+	ID     *IDValue
 }
 
 // Error returns a string representation of the empty result error
 func (e *EmptyResultError) Error() string {
-	return fmt.Sprintf("rpc: empty result [%s]", e.Method)
+	return fmt.Sprintf("rpc: empty result [%s]", methodLabel(e.Method, e.ID))
 }
 
 // IsRPCError implements the Error interface
@@ -115,15 +161,23 @@ func (e *EmptyResultError) IsRPCError() bool {
 	return true
 }
 
+// setRequestID records the ID of the request that failed
+func (e *EmptyResultError) setRequestID(id *IDValue) {
+	if e.ID == nil {
+		e.ID = id
+	}
+}
+
 // MarshalError represents an error during JSON serialization
 type MarshalError struct {
 	Method string
+	ID     *IDValue
 	Err    error
 }
 
 // Error returns a string representation of the marshal error
 func (e *MarshalError) Error() string {
-	return fmt.Sprintf("rpc: failed to marshal request [%s]: %v", e.Method, e.Err)
+	return fmt.Sprintf("rpc: failed to marshal request [%s]: %v", methodLabel(e.Method, e.ID), e.Err)
 }
 
 // IsRPCError implements the Error interface
@@ -131,6 +185,13 @@ func (e *MarshalError) IsRPCError() bool {
 	return true
 }
 
+// setRequestID records the ID of the request that failed
+func (e *MarshalError) setRequestID(id *IDValue) {
+	if e.ID == nil {
+		e.ID = id
+	}
+}
+
 // Unwrap returns the underlying error
 func (e *MarshalError) Unwrap() error {
 	return e.Err
@@ -139,6 +200,7 @@ func (e *MarshalError) Unwrap() error {
 // RPCError represents an error in a JSON-RPC error response
 type RPCError struct {
 	Method  string
+	ID      *IDValue
 	Code    int
 	Message string
 	Data    any
@@ -147,9 +209,9 @@ type RPCError struct {
 // Error returns a string representation of the RPC error
 func (e *RPCError) Error() string {
 	if e.Data != nil {
-		return fmt.Sprintf("rpc: JSON-RPC error [%s] code=%d: %s, data=%v", e.Method, e.Code, e.Message, e.Data)
+		return fmt.Sprintf("rpc: JSON-RPC error [%s] code=%d: %s, data=%s", methodLabel(e.Method, e.ID), e.Code, e.Message, e.compactData())
 	}
-	return fmt.Sprintf("rpc: JSON-RPC error [%s] code=%d: %s", e.Method, e.Code, e.Message)
+	return fmt.Sprintf("rpc: JSON-RPC error [%s] code=%d: %s", methodLabel(e.Method, e.ID), e.Code, e.Message)
 }
 
 // IsRPCError implements the Error interface
@@ -157,6 +219,38 @@ func (e *RPCError) IsRPCError() bool {
 	return true
 }
 
+// setRequestID records the ID of the request that failed
+func (e *RPCError) setRequestID(id *IDValue) {
+	if e.ID == nil {
+		e.ID = id
+	}
+}
+
+// DataJSON returns Data as indented JSON, e.g. to log a stack trace sent by the server,
+// or an empty string if there is no data
+func (e *RPCError) DataJSON() string {
+	if e.Data == nil {
+		return ""
+	}
+	data, err := json.MarshalIndent(e.Data, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%v", e.Data)
+	}
+	return string(data)
+}
+
+// compactData formats Data for Error: strings as they are, other values as compact JSON
+func (e *RPCError) compactData() string {
+	if s, ok := e.Data.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		return fmt.Sprintf("%v", e.Data)
+	}
+	return string(data)
+}
+
 // InvalidRequestError represents an error when the request is invalid
 type InvalidRequestError struct {
 	Message string
@@ -175,11 +269,12 @@ func (e *InvalidRequestError) IsRPCError() bool {
 // EmptyResponseError represents an error when no response is received
 type EmptyResponseError struct {
 	Method string
+	ID     *IDValue
 }
 
 // Error returns a string representation of the empty response error
 func (e *EmptyResponseError) Error() string {
-	return fmt.Sprintf("rpc: empty response [%s]", e.Method)
+	return fmt.Sprintf("rpc: empty response [%s]", methodLabel(e.Method, e.ID))
 }
 
 // IsRPCError implements the Error interface
@@ -187,14 +282,22 @@ func (e *EmptyResponseError) IsRPCError() bool {
 	return true
 }
 
+// setRequestID records the ID of the request that failed
+func (e *EmptyResponseError) setRequestID(id *IDValue) {
+	if e.ID == nil {
+		e.ID = id
+	}
+}
+
 // MissingResponseError represents an error when a response is missing for a request
 type MissingResponseError struct {
 	Method string
+	ID     *IDValue
 }
 
 // Error returns a string representation of the missing response error
 func (e *MissingResponseError) Error() string {
-	return fmt.Sprintf("rpc: missing response for method [%s]", e.Method)
+	return fmt.Sprintf("rpc: missing response for method [%s]", methodLabel(e.Method, e.ID))
 }
 
 // IsRPCError implements the Error interface
@@ -202,6 +305,73 @@ func (e *MissingResponseError) IsRPCError() bool {
 	return true
 }
 
+// setRequestID records the ID of the request that failed
+func (e *MissingResponseError) setRequestID(id *IDValue) {
+	if e.ID == nil {
+		e.ID = id
+	}
+}
+
+// ResultValidationError represents a decoded result rejected by its Validate method
+type ResultValidationError struct {
+	Method string
+	ID     *IDValue
+	Err    error
+}
+
+// Error returns a string representation of the result validation error
+func (e *ResultValidationError) Error() string {
+	return fmt.Sprintf("rpc: invalid result [%s]: %v", methodLabel(e.Method, e.ID), e.Err)
+}
+
+// IsRPCError implements the Error interface
+func (e *ResultValidationError) IsRPCError() bool {
+	return true
+}
+
+// setRequestID records the ID of the request that failed
+func (e *ResultValidationError) setRequestID(id *IDValue) {
+	if e.ID == nil {
+		e.ID = id
+	}
+}
+
+// Unwrap returns the underlying error
+func (e *ResultValidationError) Unwrap() error {
+	return e.Err
+}
+
+// RequestTooLargeError represents a request whose encoded body exceeds the size limit set by
+// WithMaxRequestBytes. It is returned before anything is sent.
+type RequestTooLargeError struct {
+	Method string
+	ID     *IDValue
+	Size   int
+	Limit  int
+	Batch  bool
+}
+
+// Error returns a string representation of the request too large error
+func (e *RequestTooLargeError) Error() string {
+	msg := fmt.Sprintf("rpc: request too large [%s]: %d bytes exceeds limit of %d bytes", methodLabel(e.Method, e.ID), e.Size, e.Limit)
+	if e.Batch {
+		msg += "; consider splitting the batch into smaller batches"
+	}
+	return msg
+}
+
+// IsRPCError implements the Error interface
+func (e *RequestTooLargeError) IsRPCError() bool {
+	return true
+}
+
+// setRequestID records the ID of the request that failed
+func (e *RequestTooLargeError) setRequestID(id *IDValue) {
+	if e.ID == nil {
+		e.ID = id
+	}
+}
+
 // IsRPCError determines if the given error is an RPC error
 func IsRPCError(err error) bool {
 	for err != nil {
@@ -212,3 +382,104 @@ func IsRPCError(err error) bool {
 	}
 	return false
 }
+
+// requestIDSetter is implemented by errors that can carry the ID of the failed request
+type requestIDSetter interface {
+	setRequestID(id *IDValue)
+}
+
+// stampRequestID records id on the first error in err's chain that can carry it.
+// IDs without a value (notifications) are not recorded.
+func stampRequestID(err error, id *IDValue) error {
+	if err == nil || id == nil || id.IsNotification() {
+		return err
+	}
+	var setter requestIDSetter
+	if errors.As(err, &setter) {
+		setter.setRequestID(id)
+	}
+	return err
+}
+
+// BatchAbortedError is reported for a request of a batch that was abandoned because a
+// critical request (see AsCritical) of the same batch failed first
+type BatchAbortedError struct {
+	Method string
+	ID     *IDValue
+	// Critical is the method of the critical request that failed
+	Critical string
+}
+
+// Error returns a string representation of the batch aborted error
+func (e *BatchAbortedError) Error() string {
+	return fmt.Sprintf("rpc: batch aborted [%s]: critical request %s failed", methodLabel(e.Method, e.ID), e.Critical)
+}
+
+// IsRPCError implements the Error interface
+func (e *BatchAbortedError) IsRPCError() bool {
+	return true
+}
+
+// setRequestID records the ID of the request that failed
+func (e *BatchAbortedError) setRequestID(id *IDValue) {
+	if e.ID == nil {
+		e.ID = id
+	}
+}
+
+// ShadowDroppedError is passed to the onShadow callback of a TeeTransport when a request is
+// not mirrored because the limit set by WithMaxShadows was reached
+type ShadowDroppedError struct {
+	Method string
+	ID     *IDValue
+	// Limit is the number of shadow calls that were in flight
+	Limit int
+}
+
+// Error returns a string representation of the shadow dropped error
+func (e *ShadowDroppedError) Error() string {
+	return fmt.Sprintf("rpc: shadow call dropped [%s]: %d shadow calls in flight", methodLabel(e.Method, e.ID), e.Limit)
+}
+
+// IsRPCError implements the Error interface
+func (e *ShadowDroppedError) IsRPCError() bool {
+	return true
+}
+
+// TaggedError wraps the error of a call whose Invoke has a Tag, so the caller can tell which
+// call failed, e.g. in the joined error of a batch. Use errors.As to get the Tag.
+type TaggedError struct {
+	Tag any
+	Err error
+}
+
+// Error returns the message of the wrapped error
+func (e *TaggedError) Error() string {
+	return e.Err.Error()
+}
+
+// IsRPCError implements the Error interface
+func (e *TaggedError) IsRPCError() bool {
+	return true
+}
+
+// Unwrap returns the underlying error
+func (e *TaggedError) Unwrap() error {
+	return e.Err
+}
+
+// tagError wraps err in a TaggedError if tag is set
+func tagError(err error, tag any) error {
+	if err == nil || tag == nil {
+		return err
+	}
+	return &TaggedError{Tag: tag, Err: err}
+}
+
+// methodLabel formats the method name, followed by the request ID when it is known
+func methodLabel(method string, id *IDValue) string {
+	if id == nil || id.IsNotification() {
+		return method
+	}
+	return fmt.Sprintf("%s id=%s", method, id.String())
+}
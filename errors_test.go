@@ -3,6 +3,7 @@ package jsonrpc_client
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -182,6 +183,42 @@ func TestRPCError(t *testing.T) {
 	}
 }
 
+func TestRPCErrorData(t *testing.T) {
+	err := &RPCError{
+		Method:  "test.method",
+		Code:    -32603,
+		Message: "Internal error",
+		Data: map[string]any{
+			"stack":   "main.go:10",
+			"details": map[string]any{"retry": false},
+		},
+	}
+
+	expected := `rpc: JSON-RPC error [test.method] code=-32603: Internal error, data={"details":{"retry":false},"stack":"main.go:10"}`
+	if err.Error() != expected {
+		t.Errorf("expected error message: %s, got: %s", expected, err.Error())
+	}
+
+	expectedJSON := "{\n  \"details\": {\n    \"retry\": false\n  },\n  \"stack\": \"main.go:10\"\n}"
+	if got := err.DataJSON(); got != expectedJSON {
+		t.Errorf("expected data JSON:\n%s\ngot:\n%s", expectedJSON, got)
+	}
+
+	t.Run("no data", func(t *testing.T) {
+		err := &RPCError{Method: "test.method", Code: -32603, Message: "Internal error"}
+		if got := err.DataJSON(); got != "" {
+			t.Errorf("expected empty data JSON, got: %s", got)
+		}
+	})
+
+	t.Run("unencodable data", func(t *testing.T) {
+		err := &RPCError{Method: "test.method", Code: -32603, Message: "Internal error", Data: func() {}}
+		if !strings.Contains(err.Error(), "data=0x") {
+			t.Errorf("expected data to fall back to %%v, got: %s", err.Error())
+		}
+	})
+}
+
 func TestIsRPCError(t *testing.T) {
 	// For RPC error
 	rpcErr := &RPCError{
@@ -261,3 +298,121 @@ func TestMissingResponseError(t *testing.T) {
 		t.Error("IsRPCError() returned false")
 	}
 }
+
+func TestResultValidationError(t *testing.T) {
+	innerErr := errors.New("negative balance")
+	err := &ResultValidationError{
+		Method: "test.method",
+		Err:    innerErr,
+	}
+
+	// Test Error() method
+	expected := "rpc: invalid result [test.method]: negative balance"
+	if err.Error() != expected {
+		t.Errorf("expected error message: %s, got: %s", expected, err.Error())
+	}
+
+	// Test IsRPCError() method
+	if !err.IsRPCError() {
+		t.Error("IsRPCError() returned false")
+	}
+
+	// Test Unwrap() method
+	if err.Unwrap() != innerErr {
+		t.Errorf("expected unwrapped error: %v, got: %v", innerErr, err.Unwrap())
+	}
+}
+
+func TestRequestTooLargeError(t *testing.T) {
+	err := &RequestTooLargeError{Method: "test.method", Size: 2048, Limit: 1024}
+
+	expected := "rpc: request too large [test.method]: 2048 bytes exceeds limit of 1024 bytes"
+	if err.Error() != expected {
+		t.Errorf("expected error message: %s, got: %s", expected, err.Error())
+	}
+
+	err.Batch = true
+	expected += "; consider splitting the batch into smaller batches"
+	if err.Error() != expected {
+		t.Errorf("expected error message: %s, got: %s", expected, err.Error())
+	}
+
+	if !err.IsRPCError() {
+		t.Error("IsRPCError() returned false")
+	}
+}
+
+func TestErrorRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "RPCError",
+			err:      &RPCError{Method: "test.method", ID: NewID(7), Code: -32600, Message: "Invalid Request"},
+			expected: "rpc: JSON-RPC error [test.method id=7] code=-32600: Invalid Request",
+		},
+		{
+			name:     "InvokeError",
+			err:      &InvokeError{Method: "test.method", ID: NewID("abc"), Err: errors.New("connection refused")},
+			expected: "rpc: invoke error [test.method id=abc]: connection refused",
+		},
+		{
+			name:     "StatusCodeError",
+			err:      &StatusCodeError{Method: "test.method", ID: NewID(7), StatusCode: 502},
+			expected: "rpc: non-200 status code [test.method id=7]: 502",
+		},
+		{
+			name:     "explicit null ID",
+			err:      &EmptyResultError{Method: "test.method", ID: NewNullID()},
+			expected: "rpc: empty result [test.method id=null]",
+		},
+		{
+			name:     "notification ID is not shown",
+			err:      &MarshalError{Method: "test.method", ID: NewNotificationID(), Err: errors.New("bad")},
+			expected: "rpc: failed to marshal request [test.method]: bad",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err.Error() != tt.expected {
+				t.Errorf("expected error message: %s, got: %s", tt.expected, tt.err.Error())
+			}
+		})
+	}
+}
+
+func TestStampRequestID(t *testing.T) {
+	t.Run("sets ID on wrapped error", func(t *testing.T) {
+		inner := &StatusCodeError{Method: "test.method", StatusCode: 500}
+		err := stampRequestID(fmt.Errorf("wrapped: %w", inner), NewID(3))
+		if !inner.ID.Equal(NewID(3)) {
+			t.Errorf("expected ID: 3, got: %v", inner.ID)
+		}
+		if err == nil {
+			t.Error("expected error to be returned")
+		}
+	})
+
+	t.Run("keeps existing ID", func(t *testing.T) {
+		inner := &RPCError{Method: "test.method", ID: NewID(1)}
+		stampRequestID(inner, NewID(2))
+		if !inner.ID.Equal(NewID(1)) {
+			t.Errorf("expected ID: 1, got: %v", inner.ID)
+		}
+	})
+
+	t.Run("ignores notifications and nil", func(t *testing.T) {
+		inner := &RPCError{Method: "test.method"}
+		stampRequestID(inner, NewNotificationID())
+		stampRequestID(inner, nil)
+		if inner.ID != nil {
+			t.Errorf("expected no ID, got: %v", inner.ID)
+		}
+		if stampRequestID(nil, NewID(1)) != nil {
+			t.Error("expected nil error to stay nil")
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package jsonrpc_client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// encodeFormRequest converts a JSON-RPC request into form values.
+// Params must encode to a JSON object (or be omitted); string members are sent as-is
+// and any other member is sent as its JSON text.
+func encodeFormRequest(request *JSONRPCRequest) (url.Values, error) {
+	values := url.Values{}
+	if request.Version != "" {
+		values.Set("jsonrpc", request.Version)
+	}
+	values.Set("method", request.Method)
+	if request.ID != nil && request.ID.Value() != nil {
+		values.Set("id", request.ID.String())
+	}
+
+	if request.Params == nil {
+		return values, nil
+	}
+
+	raw, err := json.Marshal(request.Params)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(bytes.TrimSpace(raw), []byte("{")) {
+		return nil, fmt.Errorf("form-encoded params must be an object, got: %s", raw)
+	}
+
+	var params map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, err
+	}
+	for key, value := range params {
+		if key == "jsonrpc" || key == "method" || key == "id" {
+			return nil, fmt.Errorf("param %q conflicts with a reserved form field", key)
+		}
+		var str string
+		if err := json.Unmarshal(value, &str); err == nil {
+			values.Set(key, str)
+			continue
+		}
+		values.Set(key, string(value))
+	}
+	return values, nil
+}
@@ -0,0 +1,135 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEncodeFormRequest(t *testing.T) {
+	t.Run("object params", func(t *testing.T) {
+		request := &JSONRPCRequest{
+			Version: "2.0",
+			ID:      NewID(7),
+			Method:  "add",
+			Params: map[string]any{
+				"name":  "value",
+				"count": 3,
+				"tags":  []string{"a", "b"},
+			},
+		}
+
+		values, err := encodeFormRequest(request)
+		if err != nil {
+			t.Fatalf("encodeFormRequest error: %v", err)
+		}
+
+		expected := map[string]string{
+			"jsonrpc": "2.0",
+			"method":  "add",
+			"id":      "7",
+			"name":    "value",
+			"count":   "3",
+			"tags":    `["a","b"]`,
+		}
+		for key, want := range expected {
+			if got := values.Get(key); got != want {
+				t.Errorf("expected %s: %s, got: %s", key, want, got)
+			}
+		}
+	})
+
+	t.Run("notification omits id", func(t *testing.T) {
+		request := &JSONRPCRequest{
+			Version: "2.0",
+			ID:      NewNotificationID(),
+			Method:  "notify",
+		}
+
+		values, err := encodeFormRequest(request)
+		if err != nil {
+			t.Fatalf("encodeFormRequest error: %v", err)
+		}
+		if values.Has("id") {
+			t.Errorf("expected id to be omitted, got: %s", values.Get("id"))
+		}
+	})
+
+	t.Run("non-object params", func(t *testing.T) {
+		request := &JSONRPCRequest{
+			Version: "2.0",
+			ID:      NewID(1),
+			Method:  "add",
+			Params:  []int{1, 2},
+		}
+
+		if _, err := encodeFormRequest(request); err == nil {
+			t.Fatal("expected error for array params, got nil")
+		}
+	})
+
+	t.Run("reserved param name", func(t *testing.T) {
+		request := &JSONRPCRequest{
+			Version: "2.0",
+			ID:      NewID(1),
+			Method:  "add",
+			Params:  map[string]string{"method": "other"},
+		}
+
+		if _, err := encodeFormRequest(request); err == nil {
+			t.Fatal("expected error for reserved param name, got nil")
+		}
+	})
+}
+
+func TestHTTPTransportFormEncodedParams(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Content-Type"); got != "application/x-www-form-urlencoded" {
+			t.Errorf("expected Content-Type: application/x-www-form-urlencoded, got: %s", got)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+		if got := r.PostForm.Get("method"); got != "add" {
+			t.Errorf("expected method: add, got: %s", got)
+		}
+		if got := r.PostForm.Get("a"); got != "10" {
+			t.Errorf("expected a: 10, got: %s", got)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"jsonrpc":"2.0","id":` + r.PostForm.Get("id") + `,"result":30}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(NewHTTPTransport(server.URL, WithFormEncodedParams()))
+
+	t.Run("single request", func(t *testing.T) {
+		invoke := &Invoke[map[string]int, int]{
+			Name:    "add",
+			Request: map[string]int{"a": 10, "b": 20},
+		}
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if invoke.Response != 30 {
+			t.Errorf("expected result: 30, got: %d", invoke.Response)
+		}
+	})
+
+	t.Run("batch request", func(t *testing.T) {
+		invoke := &Invoke[map[string]int, int]{
+			Name:    "add",
+			Request: map[string]int{"a": 10, "b": 20},
+		}
+		err := client.InvokeBatch(context.Background(), []MethodCaller{invoke})
+
+		var invalidErr *InvalidRequestError
+		if !errors.As(err, &invalidErr) {
+			t.Fatalf("expected error type: *InvalidRequestError, got: %T", err)
+		}
+	})
+}
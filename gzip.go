@@ -0,0 +1,83 @@
+package jsonrpc_client
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+)
+
+// WithTolerantGzip makes the transport decompress gzip responses itself and tolerate
+// servers that label a plain body as Content-Encoding: gzip. When the body does not start
+// with the gzip magic bytes, or its gzip header is invalid, it is read as plain JSON and
+// warn, if set, is called with a description of the mismatch.
+//
+// The transport requests gzip with an Accept-Encoding header, unless one is already set,
+// which also turns off the transparent decompression of net/http that would otherwise fail
+// on such bodies.
+func WithTolerantGzip(warn func(method, message string)) HTTPTransportOption {
+	return func(t *HTTPTransport) {
+		t.tolerantGzip = true
+		t.gzipWarn = warn
+	}
+}
+
+// GzipCapability is the capability a server advertises in its initialize result to signal
+// that it accepts gzip-compressed requests
+const GzipCapability = "gzip"
+
+// WithGzipRequests compresses request bodies with gzip, sent with Content-Encoding: gzip,
+// but only while supported reports that the server accepts them. Otherwise requests are sent
+// uncompressed, so servers that reject compressed bodies with 415 or 400 keep working.
+//
+// supported is called for every request. For a server known to accept gzip, pass a function
+// that returns true. To learn it from the initialize handshake, check the capabilities the
+// client stored:
+//
+//	var client *Client
+//	transport := NewHTTPTransport(url, WithGzipRequests(func() bool {
+//		return client.Capabilities().Has(GzipCapability)
+//	}))
+//	client = NewClient(transport)
+//
+// Until Initialize has succeeded the capabilities are empty and requests are not compressed.
+// WithMaxRequestBytes applies to the uncompressed body, and signers see the compressed one.
+func WithGzipRequests(supported func() bool) HTTPTransportOption {
+	return func(t *HTTPTransport) {
+		t.gzipRequests = supported
+	}
+}
+
+// gzipTo writes data to w compressed with gzip
+func gzipTo(w io.Writer, data []byte) error {
+	zw := gzip.NewWriter(w)
+	if _, err := zw.Write(data); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// gunzip returns a reader for a body labeled as gzip, which falls back to the raw body when
+// it is not actually compressed
+func (t *HTTPTransport) gunzip(method string, body io.Reader) io.ReadCloser {
+	br := bufio.NewReader(body)
+	magic, _ := br.Peek(2)
+	if len(magic) == 0 {
+		return io.NopCloser(br)
+	}
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err == nil {
+			return io.NopCloser(zr)
+		}
+		t.warnGzip(method, "invalid gzip header, reading body as plain: "+err.Error())
+		return io.NopCloser(br)
+	}
+	t.warnGzip(method, "response labeled gzip is not compressed, reading body as plain")
+	return io.NopCloser(br)
+}
+
+func (t *HTTPTransport) warnGzip(method, message string) {
+	if t.gzipWarn != nil {
+		t.gzipWarn(method, message)
+	}
+}
@@ -0,0 +1,152 @@
+package jsonrpc_client
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPTransportTolerantGzip(t *testing.T) {
+	const reply = `{"jsonrpc":"2.0","id":1,"result":"ok"}`
+	var compress bool
+	var acceptEncoding string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptEncoding = r.Header.Get("Accept-Encoding")
+		w.Header().Set("Content-Encoding", "gzip")
+		if !compress {
+			_, _ = w.Write([]byte(reply))
+			return
+		}
+		zw := gzip.NewWriter(w)
+		_, _ = zw.Write([]byte(reply))
+		_ = zw.Close()
+	}))
+	defer server.Close()
+
+	invoke := func(transport Transport) (string, error) {
+		call := &Invoke[[]int, string]{Name: "test", Request: []int{1}}
+		err := NewClient(transport).Invoke(context.Background(), call)
+		return call.Response, err
+	}
+
+	t.Run("mislabeled body fails by default", func(t *testing.T) {
+		compress = false
+		if _, err := invoke(NewHTTPTransport(server.URL)); err == nil {
+			t.Error("expected an error decoding a mislabeled body")
+		}
+	})
+
+	t.Run("mislabeled body is read as plain", func(t *testing.T) {
+		compress = false
+		var warnings []string
+		transport := NewHTTPTransport(server.URL, WithTolerantGzip(func(method, message string) {
+			warnings = append(warnings, method+": "+message)
+		}))
+
+		response, err := invoke(transport)
+		if err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if response != "ok" {
+			t.Errorf("expected response: ok, got: %s", response)
+		}
+		if len(warnings) != 1 || !strings.HasPrefix(warnings[0], "test: ") {
+			t.Errorf("expected one warning for method test, got: %v", warnings)
+		}
+		if acceptEncoding != "gzip" {
+			t.Errorf("expected Accept-Encoding: gzip, got: %s", acceptEncoding)
+		}
+	})
+
+	t.Run("compressed body is decompressed", func(t *testing.T) {
+		compress = true
+		var warnings int
+		transport := NewHTTPTransport(server.URL, WithTolerantGzip(func(method, message string) {
+			warnings++
+		}))
+
+		response, err := invoke(transport)
+		if err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if response != "ok" || warnings != 0 {
+			t.Errorf("expected response ok without warnings, got: %s and %d warnings", response, warnings)
+		}
+	})
+
+	t.Run("nil warn func", func(t *testing.T) {
+		compress = false
+		if _, err := invoke(NewHTTPTransport(server.URL, WithTolerantGzip(nil))); err != nil {
+			t.Errorf("expected no error, got: %v", err)
+		}
+	})
+}
+
+func TestHTTPTransportGzipRequests(t *testing.T) {
+	var encodings []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodings = append(encodings, r.Header.Get("Content-Encoding"))
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body = zr
+		}
+		var request JSONRPCRequest
+		if err := json.NewDecoder(body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		result := `"ok"`
+		if request.Method == InitializeMethod {
+			result = `{"capabilities":{"gzip":true}}`
+		}
+		_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%s}`, request.ID.String(), result)
+	}))
+	defer server.Close()
+
+	t.Run("learned from the handshake", func(t *testing.T) {
+		encodings = nil
+		var client *Client
+		transport := NewHTTPTransport(server.URL, WithGzipRequests(func() bool {
+			return client.Capabilities().Has(GzipCapability)
+		}))
+		client = NewClient(transport)
+
+		call := func() {
+			if err := client.Invoke(context.Background(), &Invoke[[]int, string]{Name: "test", Request: []int{1}}); err != nil {
+				t.Fatalf("Invoke error: %v", err)
+			}
+		}
+		call()
+		if _, err := client.Initialize(context.Background(), nil); err != nil {
+			t.Fatalf("Initialize error: %v", err)
+		}
+		call()
+
+		expected := []string{"", "", "gzip"}
+		if strings.Join(encodings, ",") != strings.Join(expected, ",") {
+			t.Errorf("expected encodings: %q, got: %q", expected, encodings)
+		}
+	})
+
+	t.Run("configured flag", func(t *testing.T) {
+		encodings = nil
+		transport := NewHTTPTransport(server.URL, WithGzipRequests(func() bool { return true }))
+		if err := NewClient(transport).Invoke(context.Background(), &Invoke[[]int, string]{Name: "test", Request: []int{1}}); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if len(encodings) != 1 || encodings[0] != "gzip" {
+			t.Errorf("expected a gzip request, got: %q", encodings)
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"time"
+)
+
+// HedgedTransport sends a request to several transports, e.g. one per replica, and returns
+// the first successful response. The first transport is tried at once; each further one is
+// started when the previous attempts have not answered within the delay, or right away when
+// an attempt fails. Once a response is accepted, the remaining attempts are cancelled
+// through their context.
+//
+// A hedged request may be executed by more than one backend, so only use it for idempotent
+// calls such as reads.
+type HedgedTransport struct {
+	transports []Transport
+	delay      time.Duration
+	clock      Clock
+}
+
+type HedgedOption func(*HedgedTransport)
+
+// WithHedgeClock sets the clock used to wait for the hedge delay. It is mainly useful in tests.
+func WithHedgeClock(clock Clock) HedgedOption {
+	return func(t *HedgedTransport) {
+		t.clock = clock
+	}
+}
+
+// NewHedgedTransport creates a HedgedTransport that tries transports in order, delay apart
+func NewHedgedTransport(transports []Transport, delay time.Duration, opts ...HedgedOption) *HedgedTransport {
+	t := &HedgedTransport{
+		transports: transports,
+		delay:      delay,
+		clock:      realClock{},
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// hedgeResult is the outcome of one attempt
+type hedgeResult struct {
+	output *SendRequestOutput
+	err    error
+}
+
+// SendRequest returns the first successful response, or the error of the last attempt to
+// finish if all of them fail
+func (t *HedgedTransport) SendRequest(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+	if len(t.transports) == 0 {
+		return nil, &InvalidRequestError{Message: "no transports to hedge across"}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Buffered so that attempts still running after we return do not block
+	results := make(chan hedgeResult, len(t.transports))
+	launched := 0
+	start := func() <-chan time.Time {
+		transport := t.transports[launched]
+		attemptCtx := ctx
+		if launched > 0 {
+			// Only the first attempt may hand responses to InvokeBatchProgress early
+			attemptCtx = withoutProgress(ctx)
+		}
+		launched++
+		go func() {
+			output, err := transport.SendRequest(attemptCtx, input)
+			results <- hedgeResult{output: output, err: err}
+		}()
+		if launched == len(t.transports) {
+			return nil
+		}
+		return t.clock.After(t.delay)
+	}
+
+	timer := start()
+	var lastErr error
+	for finished := 0; ; {
+		select {
+		case result := <-results:
+			finished++
+			if result.err == nil {
+				return result.output, nil
+			}
+			lastErr = result.err
+			if launched < len(t.transports) {
+				timer = start()
+			} else if finished == launched {
+				return nil, lastErr
+			}
+		case <-timer:
+			timer = start()
+		}
+	}
+}
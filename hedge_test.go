@@ -0,0 +1,137 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yacchi/go-jsonrpc-client/testutil"
+)
+
+// replica answers with its name, after waiting for release if it is set
+type replica struct {
+	name      string
+	err       error
+	release   chan struct{}
+	calls     atomic.Int32
+	cancelled chan struct{}
+}
+
+func (r *replica) SendRequest(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+	r.calls.Add(1)
+	if r.release != nil {
+		select {
+		case <-r.release:
+		case <-ctx.Done():
+			close(r.cancelled)
+			return nil, &InvokeError{Method: input.Requests[0].Method, Err: ctx.Err()}
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	result, _ := json.Marshal(r.name)
+	return &SendRequestOutput{Responses: []*JSONRPCResponse{
+		{Version: "2.0", ID: input.Requests[0].ID, Result: result},
+	}}, nil
+}
+
+func newSlowReplica(name string) *replica {
+	return &replica{name: name, release: make(chan struct{}), cancelled: make(chan struct{})}
+}
+
+func TestHedgedTransport(t *testing.T) {
+	input := &SendRequestInput{Requests: []*JSONRPCRequest{{ID: NewID(1), Method: "read"}}}
+	const delay = 50 * time.Millisecond
+
+	resultOf := func(t *testing.T, output *SendRequestOutput) string {
+		t.Helper()
+		var name string
+		if err := json.Unmarshal(output.Responses[0].Result, &name); err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+		return name
+	}
+
+	t.Run("fast primary is not hedged", func(t *testing.T) {
+		primary, secondary := &replica{name: "primary"}, &replica{name: "secondary"}
+		transport := NewHedgedTransport([]Transport{primary, secondary}, delay, WithHedgeClock(testutil.NewFakeClock(time.Unix(0, 0))))
+
+		output, err := transport.SendRequest(context.Background(), input)
+		if err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+		if got := resultOf(t, output); got != "primary" {
+			t.Errorf("expected response from: primary, got: %s", got)
+		}
+		if secondary.calls.Load() != 0 {
+			t.Errorf("expected the secondary not to be called, got: %d calls", secondary.calls.Load())
+		}
+	})
+
+	t.Run("slow primary is hedged and cancelled", func(t *testing.T) {
+		clock := testutil.NewFakeClock(time.Unix(0, 0))
+		primary, secondary := newSlowReplica("primary"), &replica{name: "secondary"}
+		transport := NewHedgedTransport([]Transport{primary, secondary}, delay, WithHedgeClock(clock))
+
+		done := make(chan *SendRequestOutput, 1)
+		go func() {
+			output, err := transport.SendRequest(context.Background(), input)
+			if err != nil {
+				t.Errorf("SendRequest error: %v", err)
+			}
+			done <- output
+		}()
+
+		for clock.Waiters() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		clock.Advance(delay)
+
+		if output := <-done; output == nil || resultOf(t, output) != "secondary" {
+			t.Errorf("expected response from: secondary, got: %v", output)
+		}
+		select {
+		case <-primary.cancelled:
+		case <-time.After(time.Second):
+			t.Error("expected the primary attempt to be cancelled")
+		}
+	})
+
+	t.Run("failure hedges at once", func(t *testing.T) {
+		unavailable := &StatusCodeError{Method: "read", StatusCode: 503}
+		primary, secondary := &replica{name: "primary", err: unavailable}, &replica{name: "secondary"}
+		transport := NewHedgedTransport([]Transport{primary, secondary}, time.Hour)
+
+		output, err := transport.SendRequest(context.Background(), input)
+		if err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+		if got := resultOf(t, output); got != "secondary" {
+			t.Errorf("expected response from: secondary, got: %s", got)
+		}
+	})
+
+	t.Run("all attempts fail", func(t *testing.T) {
+		first := &StatusCodeError{Method: "read", StatusCode: 502}
+		last := &StatusCodeError{Method: "read", StatusCode: 503}
+		transport := NewHedgedTransport([]Transport{&replica{err: first}, &replica{err: last}}, time.Hour)
+
+		_, err := transport.SendRequest(context.Background(), input)
+		if !errors.Is(err, last) {
+			t.Errorf("expected the last error, got: %v", err)
+		}
+	})
+
+	t.Run("no transports", func(t *testing.T) {
+		_, err := NewHedgedTransport(nil, delay).SendRequest(context.Background(), input)
+
+		var invalidErr *InvalidRequestError
+		if !errors.As(err, &invalidErr) {
+			t.Fatalf("expected error type: *InvalidRequestError, got: %T", err)
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// InitializeMethod is the method name used for the initialize handshake
+const InitializeMethod = "initialize"
+
+// Capabilities holds the capabilities a server advertised during the initialize handshake
+type Capabilities map[string]any
+
+// Has reports whether the server advertised the named capability with a non-null, non-false value
+func (c Capabilities) Has(name string) bool {
+	v, ok := c[name]
+	if !ok || v == nil {
+		return false
+	}
+	if b, isBool := v.(bool); isBool {
+		return b
+	}
+	return true
+}
+
+// initializeResult is the common shape of MCP/LSP initialize results
+type initializeResult struct {
+	Capabilities Capabilities `json:"capabilities"`
+}
+
+// Initialize performs the initialize handshake used by MCP/LSP-style servers and stores the
+// returned capabilities on the client. When the result has a "capabilities" member it is used,
+// otherwise the whole result object is treated as the capabilities.
+func (c *Client) Initialize(ctx context.Context, params any) (Capabilities, error) {
+	if c.noAutoID {
+		return nil, errNoAutoID(InitializeMethod)
+	}
+	invoke := &Invoke[any, json.RawMessage]{
+		Name:    InitializeMethod,
+		Request: params,
+	}
+	if err := c.Invoke(ctx, invoke); err != nil {
+		return nil, err
+	}
+
+	var result initializeResult
+	if err := json.Unmarshal(invoke.Response, &result); err != nil {
+		return nil, &UnmarshalError{Method: InitializeMethod, Err: err}
+	}
+	capabilities := result.Capabilities
+	if capabilities == nil {
+		if err := json.Unmarshal(invoke.Response, &capabilities); err != nil {
+			return nil, &UnmarshalError{Method: InitializeMethod, Err: err}
+		}
+	}
+
+	c.mu.Lock()
+	c.capabilities = capabilities
+	c.mu.Unlock()
+	return capabilities, nil
+}
+
+// Capabilities returns the capabilities stored by the last successful Initialize call,
+// or nil if the handshake has not been performed
+func (c *Client) Capabilities() Capabilities {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.capabilities
+}
@@ -0,0 +1,73 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestInitialize(t *testing.T) {
+	newTransport := func(result string) *MockTransport {
+		return &MockTransport{
+			SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+				request := input.Requests[0]
+				if request.Method != InitializeMethod {
+					t.Errorf("expected method: %s, got: %s", InitializeMethod, request.Method)
+				}
+				return &SendRequestOutput{
+					Responses: []*JSONRPCResponse{{ID: request.ID, Result: json.RawMessage(result)}},
+				}, nil
+			},
+		}
+	}
+
+	t.Run("capabilities member", func(t *testing.T) {
+		client := NewClient(newTransport(`{"protocolVersion":"2024-11-05","capabilities":{"tools":{},"logging":false}}`))
+
+		if client.Capabilities() != nil {
+			t.Errorf("expected nil capabilities before handshake")
+		}
+
+		capabilities, err := client.Initialize(context.Background(), map[string]string{"clientName": "test"})
+		if err != nil {
+			t.Fatalf("Initialize error: %v", err)
+		}
+		if !capabilities.Has("tools") {
+			t.Errorf("expected tools capability")
+		}
+		if capabilities.Has("logging") {
+			t.Errorf("expected logging capability to be disabled")
+		}
+		if capabilities.Has("prompts") {
+			t.Errorf("expected prompts capability to be absent")
+		}
+		if !client.Capabilities().Has("tools") {
+			t.Errorf("expected stored capabilities to include tools")
+		}
+	})
+
+	t.Run("bare capabilities result", func(t *testing.T) {
+		client := NewClient(newTransport(`{"gzip":true}`))
+
+		if _, err := client.Initialize(context.Background(), nil); err != nil {
+			t.Fatalf("Initialize error: %v", err)
+		}
+		if !client.Capabilities().Has("gzip") {
+			t.Errorf("expected gzip capability")
+		}
+	})
+
+	t.Run("invalid result", func(t *testing.T) {
+		client := NewClient(newTransport(`"ok"`))
+
+		_, err := client.Initialize(context.Background(), nil)
+		var unmarshalErr *UnmarshalError
+		if !errors.As(err, &unmarshalErr) {
+			t.Fatalf("expected error type: *UnmarshalError, got: %T", err)
+		}
+		if client.Capabilities() != nil {
+			t.Errorf("expected capabilities to remain unset")
+		}
+	})
+}
@@ -1,8 +1,11 @@
 package jsonrpc_client
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"strings"
 )
 
 type IDValue struct {
@@ -11,13 +14,20 @@ type IDValue struct {
 	isNull bool // nullを明示的に表現するためのフラグ
 }
 
-// NewNullID creates a new IDValue that explicitly represents null
+// NewNullID creates a new IDValue that explicitly represents null.
+// A request with a null ID is sent as "id": null and still expects a response.
 func NewNullID() *IDValue {
 	return &IDValue{
 		isNull: true,
 	}
 }
 
+// NewNotificationID creates a new IDValue that is omitted from the request,
+// marking it as a notification for which no response is expected
+func NewNotificationID() *IDValue {
+	return &IDValue{}
+}
+
 // NewID creates a new IDValue from a string or integer value
 func NewID[T ~string | ~int | ~int32 | ~uint32](id T) *IDValue {
 	switch v := any(id).(type) {
@@ -58,6 +68,11 @@ func (i *IDValue) IsZero() bool {
 	return (i.strVar == nil && i.intVar == nil) && !i.isNull
 }
 
+// IsNotification checks if the ID marks a notification (non-nil, but without a value or null)
+func (i *IDValue) IsNotification() bool {
+	return i != nil && i.IsZero()
+}
+
 // IsExplicitlyNull checks if the ID is explicitly set to null
 func (i *IDValue) IsExplicitlyNull() bool {
 	return i != nil && i.isNull
@@ -132,10 +147,19 @@ func (i *IDValue) MarshalJSON() ([]byte, error) {
 
 // JSONRPCRequest represents a JSON-RPC request
 type JSONRPCRequest struct {
-	Version string   `json:"jsonrpc"`
+	Version string   `json:"jsonrpc,omitempty"`
 	ID      *IDValue `json:"id,omitzero"`
 	Method  string   `json:"method"`
 	Params  any      `json:"params,omitempty"`
+	// Meta is a non-standard top-level member sent alongside params, for servers that
+	// accept out-of-band metadata in the request envelope (see WithRequestMeta)
+	Meta any `json:"meta,omitempty"`
+	// Tag is caller-side metadata copied from Invoke.Tag. It is never sent, but transports
+	// and callbacks that receive the request can read it.
+	Tag any `json:"-"`
+
+	// critical is set by AsCritical
+	critical bool
 }
 
 // JSONRPCError represents a JSON-RPC error
@@ -150,10 +174,128 @@ func (j *JSONRPCError) Error() string {
 	return fmt.Sprintf("JSON-RPC Error %d: %s", j.Code, j.Message)
 }
 
+// UnmarshalJSON deserializes the error from either the standard object form or the
+// non-standard [code, message] array form used by some servers
+func (j *JSONRPCError) UnmarshalJSON(bytes []byte) error {
+	var arr []json.RawMessage
+	if err := json.Unmarshal(bytes, &arr); err == nil {
+		if len(arr) != 2 {
+			return fmt.Errorf("invalid error format: expected [code, message], got %d elements", len(arr))
+		}
+		if err := json.Unmarshal(arr[0], &j.Code); err != nil {
+			return fmt.Errorf("invalid error code: %w", err)
+		}
+		if err := json.Unmarshal(arr[1], &j.Message); err != nil {
+			return fmt.Errorf("invalid error message: %w", err)
+		}
+		return nil
+	}
+
+	// Use an alias type to avoid recursing into this method
+	type jsonrpcError JSONRPCError
+	return json.Unmarshal(bytes, (*jsonrpcError)(j))
+}
+
 // JSONRPCResponse represents a JSON-RPC response
 type JSONRPCResponse struct {
 	Version string          `json:"jsonrpc"`
 	ID      *IDValue        `json:"id,omitzero"`
 	Result  json.RawMessage `json:"result,omitempty"`
 	Error   *JSONRPCError   `json:"error,omitempty"`
+
+	// decodeErr records why this batch element could not be decoded, when its ID could
+	// still be recovered. The client reports it as an UnmarshalError for the matching invoke.
+	decodeErr error
+
+	// sendErr records why no response could be obtained for this request when a batch is
+	// sent as separate calls. The client reports it as-is for the matching invoke.
+	sendErr error
+
+	// useNumber decodes numbers in the result into json.Number when the target is an interface (see WithUseNumber)
+	useNumber bool
+
+	// unwrapString decodes the JSON inside a string result for non-string targets (see WithUnwrapStringResult)
+	unwrapString bool
+
+	// emptyAsMissing treats a {} or [] result as no result (see WithTreatEmptyObjectAsEmptyResult)
+	emptyAsMissing bool
+}
+
+// missingResult reports whether the response has no result to decode: the result member is
+// absent, or it is {} or [] and emptyAsMissing is set
+func (r *JSONRPCResponse) missingResult() bool {
+	if r.Result == nil {
+		return true
+	}
+	if !r.emptyAsMissing {
+		return false
+	}
+	trimmed := bytes.TrimSpace(r.Result)
+	if len(trimmed) < 2 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return false
+	}
+	inner := bytes.TrimSpace(trimmed[1 : len(trimmed)-1])
+	return len(inner) == 0
+}
+
+// AsError returns the JSON-RPC error of the response as an *RPCError for method,
+// or nil if the response has no error
+func (r *JSONRPCResponse) AsError(method string) error {
+	if r.Error == nil {
+		return nil
+	}
+	return &RPCError{
+		Method:  method,
+		Code:    r.Error.Code,
+		Message: r.Error.Message,
+		Data:    r.Error.Data,
+	}
+}
+
+// decodeResult decodes the result member into v, which must be a pointer. A v implementing
+// ResultUnmarshaler decodes the raw result itself.
+func (r *JSONRPCResponse) decodeResult(v any) error {
+	if u, ok := v.(ResultUnmarshaler); ok {
+		return u.UnmarshalResult(r.Result)
+	}
+
+	result := r.Result
+	if r.unwrapString {
+		unwrapped, err := unwrapStringResult(result, v)
+		if err != nil {
+			return err
+		}
+		result = unwrapped
+	}
+
+	if !r.useNumber {
+		return json.Unmarshal(result, v)
+	}
+	dec := json.NewDecoder(bytes.NewReader(result))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// unwrapStringResult returns the JSON object or array held by a string result. The result is
+// returned as it is if it is not a string, if the string does not hold an object or array,
+// e.g. a timestamp or base64 data that v decodes from a string, or if v points to a string
+// or an interface, which take the string as it is.
+func unwrapStringResult(result json.RawMessage, v any) (json.RawMessage, error) {
+	trimmed := bytes.TrimSpace(result)
+	if len(trimmed) == 0 || trimmed[0] != '"' {
+		return result, nil
+	}
+	if t := reflect.TypeOf(v); t == nil || t.Kind() != reflect.Pointer ||
+		t.Elem().Kind() == reflect.String || t.Elem().Kind() == reflect.Interface {
+		return result, nil
+	}
+
+	var inner string
+	if err := json.Unmarshal(trimmed, &inner); err != nil {
+		return nil, err
+	}
+	if inner = strings.TrimSpace(inner); inner == "" || (inner[0] != '{' && inner[0] != '[') {
+		return result, nil
+	}
+	return json.RawMessage(inner), nil
 }
@@ -2,6 +2,7 @@ package jsonrpc_client
 
 import (
 	"encoding/json"
+	"errors"
 	"testing"
 )
 
@@ -370,6 +371,78 @@ func TestJSONRPCError(t *testing.T) {
 	}
 }
 
+func TestJSONRPCErrorUnmarshalJSON(t *testing.T) {
+	t.Run("object form", func(t *testing.T) {
+		var e JSONRPCError
+		if err := json.Unmarshal([]byte(`{"code":-32601,"message":"Method not found","data":"x"}`), &e); err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+		if e.Code != -32601 || e.Message != "Method not found" || e.Data != "x" {
+			t.Errorf("unexpected error: %+v", e)
+		}
+	})
+
+	t.Run("array form", func(t *testing.T) {
+		var resp JSONRPCResponse
+		if err := json.Unmarshal([]byte(`{"jsonrpc":"2.0","id":1,"error":[-32000,"Server busy"]}`), &resp); err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+		if resp.Error == nil {
+			t.Fatal("error is nil")
+		}
+		if resp.Error.Code != -32000 {
+			t.Errorf("expected error code: -32000, got: %d", resp.Error.Code)
+		}
+		if resp.Error.Message != "Server busy" {
+			t.Errorf("expected error message: Server busy, got: %s", resp.Error.Message)
+		}
+	})
+
+	t.Run("invalid array form", func(t *testing.T) {
+		invalid := []string{
+			`[-32000]`,
+			`[-32000,"busy","extra"]`,
+			`["busy",-32000]`,
+		}
+		for _, input := range invalid {
+			var e JSONRPCError
+			if err := json.Unmarshal([]byte(input), &e); err == nil {
+				t.Errorf("expected error for %s, got nil", input)
+			}
+		}
+	})
+}
+
+func TestNewNotificationID(t *testing.T) {
+	id := NewNotificationID()
+
+	if !id.IsNotification() {
+		t.Error("NewNotificationID() should create an ID that marks a notification")
+	}
+	if id.IsExplicitlyNull() {
+		t.Error("NewNotificationID() should not be explicitly null")
+	}
+	if NewNullID().IsNotification() {
+		t.Error("NewNullID() should not mark a notification")
+	}
+	if NewID(1).IsNotification() {
+		t.Error("NewID() should not mark a notification")
+	}
+	var nilID *IDValue
+	if nilID.IsNotification() {
+		t.Error("nil ID should not mark a notification")
+	}
+
+	req := JSONRPCRequest{Version: "2.0", ID: id, Method: "notify"}
+	bytes, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	if expected := `{"jsonrpc":"2.0","method":"notify"}`; string(bytes) != expected {
+		t.Errorf("expected JSON: %s, got: %s", expected, bytes)
+	}
+}
+
 func TestNewNullID(t *testing.T) {
 	id := NewNullID()
 
@@ -669,3 +742,33 @@ func TestJSONRPCResponse(t *testing.T) {
 		t.Error("error is nil")
 	}
 }
+
+func TestJSONRPCResponseAsError(t *testing.T) {
+	t.Run("error response", func(t *testing.T) {
+		resp := &JSONRPCResponse{
+			Version: "2.0",
+			ID:      NewID(1),
+			Error:   &JSONRPCError{Code: -32602, Message: "Invalid params", Data: "missing name"},
+		}
+
+		err := resp.AsError("test.method")
+
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) {
+			t.Fatalf("expected error type: *RPCError, got: %T", err)
+		}
+		if rpcErr.Method != "test.method" {
+			t.Errorf("expected method: test.method, got: %s", rpcErr.Method)
+		}
+		if rpcErr.Code != -32602 || rpcErr.Message != "Invalid params" || rpcErr.Data != "missing name" {
+			t.Errorf("unexpected error fields: %+v", rpcErr)
+		}
+	})
+
+	t.Run("success response", func(t *testing.T) {
+		resp := &JSONRPCResponse{Version: "2.0", ID: NewID(1), Result: json.RawMessage(`1`)}
+		if err := resp.AsError("test.method"); err != nil {
+			t.Errorf("expected nil error, got: %v", err)
+		}
+	})
+}
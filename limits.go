@@ -0,0 +1,42 @@
+package jsonrpc_client
+
+import "fmt"
+
+// ServerLimits describes the largest batch a server accepts, e.g. as learned from its
+// capabilities. A zero field means no limit.
+type ServerLimits struct {
+	// MaxBatch is the maximum number of requests in a batch
+	MaxBatch int
+	// MaxBytes is the maximum size of an encoded batch, as produced by EncodeRequest
+	MaxBytes int
+}
+
+// WithServerLimits makes InvokeBatch check each batch against limits before sending it and
+// fail with InvalidRequestError naming the exceeded limit, instead of an opaque rejection
+// by the server. Calls collapsed by WithDedupeBatch count once. The size is that of the
+// standard JSON encoding, so transport options that change the body, such as gzip or
+// indentation, are not taken into account.
+func WithServerLimits(limits ServerLimits) ClientOption {
+	return func(c *Client) {
+		c.serverLimits = limits
+	}
+}
+
+// checkServerLimits checks the requests of a batch as they will be sent against the
+// configured server limits
+func (c *Client) checkServerLimits(requests []*JSONRPCRequest) error {
+	limits := c.serverLimits
+	if limits.MaxBatch > 0 && len(requests) > limits.MaxBatch {
+		return &InvalidRequestError{Message: fmt.Sprintf("batch of %d requests exceeds the server limit of %d", len(requests), limits.MaxBatch)}
+	}
+	if limits.MaxBytes > 0 {
+		encoded, err := EncodeRequest(requests, true)
+		if err != nil {
+			return err
+		}
+		if len(encoded) > limits.MaxBytes {
+			return &InvalidRequestError{Message: fmt.Sprintf("batch of %d bytes exceeds the server limit of %d bytes", len(encoded), limits.MaxBytes)}
+		}
+	}
+	return nil
+}
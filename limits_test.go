@@ -0,0 +1,77 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWithServerLimits(t *testing.T) {
+	sends := 0
+	transport := &MockTransport{
+		SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			sends++
+			output := &SendRequestOutput{}
+			for _, req := range input.Requests {
+				output.Responses = append(output.Responses, &JSONRPCResponse{Version: "2.0", ID: req.ID, Result: json.RawMessage(`"ok"`)})
+			}
+			return output, nil
+		},
+	}
+	batch := func(n int, param string) []MethodCaller {
+		reqs := make([]MethodCaller, n)
+		for i := range reqs {
+			reqs[i] = &Invoke[[]string, string]{Name: "echo", Request: []string{param}}
+		}
+		return reqs
+	}
+
+	t.Run("batch count", func(t *testing.T) {
+		sends = 0
+		client := NewClient(transport, WithServerLimits(ServerLimits{MaxBatch: 2}))
+
+		if err := client.InvokeBatch(context.Background(), batch(2, "a")); err != nil {
+			t.Fatalf("InvokeBatch error: %v", err)
+		}
+		err := client.InvokeBatch(context.Background(), batch(3, "a"))
+		var invalidErr *InvalidRequestError
+		if !errors.As(err, &invalidErr) {
+			t.Fatalf("expected error type: *InvalidRequestError, got: %T", err)
+		}
+		if !strings.Contains(invalidErr.Message, "3 requests exceeds the server limit of 2") {
+			t.Errorf("expected the count and limit in the message, got: %s", invalidErr.Message)
+		}
+		if sends != 1 {
+			t.Errorf("expected only the batch within limits to be sent, got %d sends", sends)
+		}
+	})
+
+	t.Run("batch bytes", func(t *testing.T) {
+		sends = 0
+		client := NewClient(transport, WithServerLimits(ServerLimits{MaxBytes: 200}))
+
+		if err := client.InvokeBatch(context.Background(), batch(2, "a")); err != nil {
+			t.Fatalf("InvokeBatch error: %v", err)
+		}
+		err := client.InvokeBatch(context.Background(), batch(2, strings.Repeat("x", 200)))
+		var invalidErr *InvalidRequestError
+		if !errors.As(err, &invalidErr) {
+			t.Fatalf("expected error type: *InvalidRequestError, got: %T", err)
+		}
+		if !strings.Contains(invalidErr.Message, "exceeds the server limit of 200 bytes") {
+			t.Errorf("expected the byte limit in the message, got: %s", invalidErr.Message)
+		}
+		if sends != 1 {
+			t.Errorf("expected only the batch within limits to be sent, got %d sends", sends)
+		}
+	})
+
+	t.Run("no limits", func(t *testing.T) {
+		client := NewClient(transport)
+		if err := client.InvokeBatch(context.Background(), batch(50, strings.Repeat("x", 200))); err != nil {
+			t.Fatalf("InvokeBatch error: %v", err)
+		}
+	})
+}
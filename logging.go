@@ -0,0 +1,111 @@
+package jsonrpc_client
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// LogEntry describes a request for a Logger. Params has been through the redactor.
+type LogEntry struct {
+	Method string
+	ID     *IDValue
+	Params any
+	// Duration is the time the transport took to send the request and receive the response
+	Duration time.Duration
+	// Err is the error returned by the transport, if any. Errors in responses are not included.
+	Err error
+}
+
+// Logger is called synchronously for every request a client sends, once the transport has returned
+type Logger func(entry LogEntry)
+
+// Redactor returns a copy of params that is safe to log. It must not modify params.
+type Redactor func(method string, params any) any
+
+// WithLogger sets a logger that is called for every request the client sends, including each
+// request of a batch. Params are passed through the redactor first, which is DefaultRedactor
+// unless WithRedactor is set.
+func WithLogger(logger Logger) ClientOption {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithRedactor sets the function that hides secrets in params before they are logged. It only
+// affects what the logger receives, never the request that is sent.
+func WithRedactor(redactor Redactor) ClientOption {
+	return func(c *Client) {
+		c.redactor = redactor
+	}
+}
+
+// redactedValue replaces the values hidden by DefaultRedactor
+const redactedValue = "[REDACTED]"
+
+// sensitiveKeys are the substrings of member names whose values DefaultRedactor hides
+var sensitiveKeys = []string{"password", "token", "secret"}
+
+// DefaultRedactor returns params as generic JSON values with the value of every object member
+// whose name contains "password", "token" or "secret", in any case and at any depth, replaced
+// by "[REDACTED]". Params that cannot be encoded are replaced as a whole.
+func DefaultRedactor(method string, params any) any {
+	if params == nil {
+		return nil
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return redactedValue
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return redactedValue
+	}
+	return redact(v)
+}
+
+// redact hides sensitive members of the decoded JSON value v in place
+func redact(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if isSensitive(key) {
+				v[key] = redactedValue
+			} else {
+				v[key] = redact(value)
+			}
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = redact(value)
+		}
+	}
+	return v
+}
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// logRequests passes each request of input to the logger
+func (c *Client) logRequests(input *SendRequestInput, duration time.Duration, err error) {
+	redactor := c.redactor
+	if redactor == nil {
+		redactor = DefaultRedactor
+	}
+	for _, request := range input.Requests {
+		c.logger(LogEntry{
+			Method:   request.Method,
+			ID:       request.ID,
+			Params:   redactor(request.Method, request.Params),
+			Duration: duration,
+			Err:      err,
+		})
+	}
+}
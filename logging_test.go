@@ -0,0 +1,121 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithLogger(t *testing.T) {
+	type credentials struct {
+		User        string `json:"user"`
+		Password    string `json:"password"`
+		APIToken    string `json:"apiToken"`
+		Nested      any    `json:"nested"`
+		ClientNotes string `json:"notes"`
+	}
+
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"ok"}`))
+	}))
+	defer server.Close()
+
+	params := credentials{
+		User:     "alice",
+		Password: "hunter2",
+		APIToken: "tok-123",
+		Nested:   []any{map[string]any{"client_secret": "s3cret", "scope": "read"}},
+	}
+
+	t.Run("default redactor", func(t *testing.T) {
+		var entries []LogEntry
+		client := NewClient(NewHTTPTransport(server.URL), WithLogger(func(entry LogEntry) {
+			entries = append(entries, entry)
+		}))
+		if err := client.Invoke(context.Background(), &Invoke[credentials, string]{ID: NewID(1), Name: "login", Request: params}); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+
+		for _, secret := range []string{"hunter2", "tok-123", "s3cret"} {
+			if !strings.Contains(body, secret) {
+				t.Errorf("expected the sent request to contain %s, got: %s", secret, body)
+			}
+		}
+		if len(entries) != 1 || entries[0].Method != "login" || entries[0].ID.String() != "1" {
+			t.Fatalf("expected one entry for login, got: %+v", entries)
+		}
+		expected := map[string]any{
+			"user":     "alice",
+			"password": "[REDACTED]",
+			"apiToken": "[REDACTED]",
+			"nested":   []any{map[string]any{"client_secret": "[REDACTED]", "scope": "read"}},
+			"notes":    "",
+		}
+		if !reflect.DeepEqual(entries[0].Params, expected) {
+			t.Errorf("expected logged params: %v, got: %v", expected, entries[0].Params)
+		}
+	})
+
+	t.Run("custom redactor", func(t *testing.T) {
+		var logged []any
+		client := NewClient(NewHTTPTransport(server.URL),
+			WithLogger(func(entry LogEntry) { logged = append(logged, entry.Params) }),
+			WithRedactor(func(method string, params any) any { return method + ": hidden" }),
+		)
+		if err := client.Invoke(context.Background(), &Invoke[credentials, string]{ID: NewID(1), Name: "login", Request: params}); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if !strings.Contains(body, "hunter2") {
+			t.Errorf("expected the sent request to be unredacted, got: %s", body)
+		}
+		if len(logged) != 1 || logged[0] != "login: hidden" {
+			t.Errorf("expected the custom redaction, got: %v", logged)
+		}
+	})
+
+	t.Run("batch and transport errors", func(t *testing.T) {
+		var entries []LogEntry
+		client := NewClient(NewHTTPTransport("http://127.0.0.1:1"), WithLogger(func(entry LogEntry) {
+			entries = append(entries, entry)
+		}))
+		_ = client.InvokeBatch(context.Background(), []MethodCaller{
+			&Invoke[[]int, string]{Name: "first", Request: []int{1}},
+			&Invoke[[]int, string]{Name: "second", Request: []int{2}},
+		})
+
+		if len(entries) != 2 || entries[0].Method != "first" || entries[1].Method != "second" {
+			t.Fatalf("expected an entry per request, got: %+v", entries)
+		}
+		if entries[0].Err == nil {
+			t.Error("expected the transport error to be logged")
+		}
+		if !reflect.DeepEqual(entries[1].Params, []any{float64(2)}) {
+			t.Errorf("expected params: [2], got: %v", entries[1].Params)
+		}
+	})
+}
+
+func TestDefaultRedactor(t *testing.T) {
+	if got := DefaultRedactor("m", nil); got != nil {
+		t.Errorf("expected nil, got: %v", got)
+	}
+	if got := DefaultRedactor("m", func() {}); got != "[REDACTED]" {
+		t.Errorf("expected unencodable params to be redacted, got: %v", got)
+	}
+	raw := json.RawMessage(`{"SECRET_KEY":"x","list":[{"token":1}]}`)
+	expected := map[string]any{"SECRET_KEY": "[REDACTED]", "list": []any{map[string]any{"token": "[REDACTED]"}}}
+	if got := DefaultRedactor("m", raw); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected: %v, got: %v", expected, got)
+	}
+	if string(raw) != `{"SECRET_KEY":"x","list":[{"token":1}]}` {
+		t.Errorf("expected params not to be modified, got: %s", raw)
+	}
+}
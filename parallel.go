@@ -0,0 +1,125 @@
+package jsonrpc_client
+
+import "context"
+
+// ParallelBatchTransport sends each request of a batch as its own single request, all at
+// once, for servers that do not accept batch arrays. Over an HTTP/2 connection the calls are
+// multiplexed, giving batch-like throughput. Single requests are passed through unchanged.
+type ParallelBatchTransport struct {
+	inner Transport
+}
+
+// NewParallelBatchTransport creates a ParallelBatchTransport that sends requests through inner
+func NewParallelBatchTransport(inner Transport) *ParallelBatchTransport {
+	return &ParallelBatchTransport{inner: inner}
+}
+
+// SendRequest sends a batch as concurrent single requests and collects their responses in
+// request order. A request that fails is answered by a response carrying its error, so the
+// client reports it for that request alone. Errors of notifications are discarded.
+//
+// When a request marked with AsCritical fails, the requests still in flight are cancelled
+// and the batch returns at once: responses already received are kept, and the abandoned
+// requests are answered with BatchAbortedError.
+//
+// Each response is handed to DeliverResponse as it arrives, so InvokeBatchProgress reports
+// the invokes in the order they complete.
+func (t *ParallelBatchTransport) SendRequest(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+	if !input.Batch {
+		return t.inner.SendRequest(ctx, input)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		index int
+		resp  *JSONRPCResponse
+	}
+	// Buffered so that requests still running after an abort do not block
+	results := make(chan result, len(input.Requests))
+	for i, request := range input.Requests {
+		go func() {
+			results <- result{index: i, resp: t.send(ctx, request)}
+		}()
+	}
+
+	responses := make([]*JSONRPCResponse, len(input.Requests))
+	done := make([]bool, len(input.Requests))
+	for range input.Requests {
+		r := <-results
+		responses[r.index], done[r.index] = r.resp, true
+		DeliverResponse(ctx, input, r.resp)
+		if request := input.Requests[r.index]; request.critical && failed(r.resp) {
+			abortPending(input.Requests, responses, done, request.Method)
+			break
+		}
+	}
+
+	output := &SendRequestOutput{}
+	for _, resp := range responses {
+		if resp != nil {
+			output.Responses = append(output.Responses, resp)
+		}
+	}
+	return output, nil
+}
+
+// failed reports whether resp carries an error, from the transport or the server
+func failed(resp *JSONRPCResponse) bool {
+	return resp != nil && (resp.sendErr != nil || resp.Error != nil)
+}
+
+// abortPending answers the requests that are not done with BatchAbortedError
+func abortPending(requests []*JSONRPCRequest, responses []*JSONRPCResponse, done []bool, critical string) {
+	for i, request := range requests {
+		if done[i] || request.ID.IsNotification() {
+			continue
+		}
+		responses[i] = &JSONRPCResponse{ID: request.ID, sendErr: &BatchAbortedError{Method: request.Method, ID: request.ID, Critical: critical}}
+	}
+}
+
+// AsCritical marks req as critical for ParallelBatchTransport: if it fails, the rest of its
+// batch is abandoned instead of waited for. Other transports deliver a batch as a whole and
+// ignore the mark.
+func AsCritical(req MethodCaller) MethodCaller {
+	return &criticalCall{MethodCaller: req}
+}
+
+// criticalCall marks the request of the wrapped MethodCaller as critical
+type criticalCall struct {
+	MethodCaller
+}
+
+func (c *criticalCall) JSONRPCRequest() *JSONRPCRequest {
+	request := c.MethodCaller.JSONRPCRequest()
+	request.critical = true
+	return request
+}
+
+func (c *criticalCall) resetResponse() {
+	resetResponse(c.MethodCaller)
+}
+
+// send sends request on its own and returns its response, or nil for a notification
+func (t *ParallelBatchTransport) send(ctx context.Context, request *JSONRPCRequest) *JSONRPCResponse {
+	output, err := t.inner.SendRequest(ctx, &SendRequestInput{Requests: []*JSONRPCRequest{request}})
+	if request.ID.IsNotification() {
+		return nil
+	}
+	if err != nil {
+		return &JSONRPCResponse{ID: request.ID, sendErr: err}
+	}
+	if output == nil || len(output.Responses) == 0 || output.Responses[0] == nil {
+		return &JSONRPCResponse{ID: request.ID, sendErr: &EmptyResponseError{Method: request.Method}}
+	}
+	resp := output.Responses[0]
+	if resp.ID == nil || !resp.ID.Equal(request.ID) {
+		// The response answers this request whatever ID the server echoed, e.g. none
+		matched := *resp
+		matched.ID = request.ID
+		resp = &matched
+	}
+	return resp
+}
@@ -0,0 +1,217 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParallelBatchTransport(t *testing.T) {
+	t.Run("sends requests individually and matches by ID", func(t *testing.T) {
+		fastSeen := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			if body[0] == '[' {
+				t.Errorf("expected a single request, got: %s", body)
+			}
+			var req JSONRPCRequest
+			json.Unmarshal(body, &req)
+			// The first request is only answered once the second has arrived, which
+			// requires them to be in flight at the same time
+			switch req.Method {
+			case "fast":
+				close(fastSeen)
+			case "slow":
+				select {
+				case <-fastSeen:
+				case <-time.After(time.Second):
+					t.Errorf("expected requests to run concurrently")
+				}
+			}
+			json.NewEncoder(w).Encode(&JSONRPCResponse{Version: "2.0", ID: req.ID, Result: json.RawMessage(`"` + req.Method + `"`)})
+		}))
+		defer server.Close()
+
+		client := NewClient(NewParallelBatchTransport(NewHTTPTransport(server.URL)))
+
+		slow := &Invoke[[]int, string]{Name: "slow", Request: []int{1}}
+		fast := &Invoke[[]int, string]{Name: "fast", Request: []int{2}}
+		notify := AsNotification(&Invoke[[]int, string]{Name: "notify", Request: []int{3}})
+		if err := client.InvokeBatch(context.Background(), []MethodCaller{slow, fast, notify}); err != nil {
+			t.Fatalf("InvokeBatch error: %v", err)
+		}
+		if slow.Response != "slow" || fast.Response != "fast" {
+			t.Errorf("expected responses: slow and fast, got: %s and %s", slow.Response, fast.Response)
+		}
+	})
+
+	t.Run("per-request errors", func(t *testing.T) {
+		failure := errors.New("connection reset")
+		inner := &MockTransport{
+			SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+				if input.Batch {
+					t.Errorf("expected a single request")
+				}
+				req := input.Requests[0]
+				if req.Method == "broken" {
+					return nil, &InvokeError{Method: req.Method, Err: failure}
+				}
+				return &SendRequestOutput{Responses: []*JSONRPCResponse{
+					{Version: "2.0", ID: req.ID, Result: json.RawMessage(`"ok"`)},
+				}}, nil
+			},
+		}
+		client := NewClient(NewParallelBatchTransport(inner))
+
+		ok := &Invoke[[]int, string]{Name: "ok", Request: []int{1}}
+		broken := &Invoke[[]int, string]{Name: "broken", Request: []int{2}}
+		err := client.InvokeBatch(context.Background(), []MethodCaller{ok, broken})
+
+		var invokeErr *InvokeError
+		if !errors.As(err, &invokeErr) {
+			t.Fatalf("expected error type: *InvokeError, got: %T", err)
+		}
+		if invokeErr.Method != "broken" || !errors.Is(err, failure) {
+			t.Errorf("expected wrapped failure for broken, got: %v", err)
+		}
+		if invokeErr.ID == nil || invokeErr.ID.String() != "2" {
+			t.Errorf("expected ID: 2, got: %v", invokeErr.ID)
+		}
+		if ok.Response != "ok" {
+			t.Errorf("expected response: ok, got: %s", ok.Response)
+		}
+	})
+
+	t.Run("response without ID", func(t *testing.T) {
+		inner := &MockTransport{
+			SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+				return &SendRequestOutput{Responses: []*JSONRPCResponse{
+					{Version: "2.0", Result: json.RawMessage(`"` + input.Requests[0].Method + `"`)},
+				}}, nil
+			},
+		}
+		client := NewClient(NewParallelBatchTransport(inner))
+
+		first := &Invoke[[]int, string]{Name: "first", Request: []int{1}}
+		second := &Invoke[[]int, string]{Name: "second", Request: []int{2}}
+		if err := client.InvokeBatch(context.Background(), []MethodCaller{first, second}); err != nil {
+			t.Fatalf("InvokeBatch error: %v", err)
+		}
+		if first.Response != "first" || second.Response != "second" {
+			t.Errorf("expected responses: first and second, got: %s and %s", first.Response, second.Response)
+		}
+	})
+
+	t.Run("single requests pass through", func(t *testing.T) {
+		var seen []*SendRequestInput
+		inner := &MockTransport{
+			SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+				seen = append(seen, input)
+				return &SendRequestOutput{Responses: []*JSONRPCResponse{
+					{Version: "2.0", ID: input.Requests[0].ID, Result: json.RawMessage(`"ok"`)},
+				}}, nil
+			},
+		}
+		client := NewClient(NewParallelBatchTransport(inner))
+
+		invoke := &Invoke[[]int, string]{Name: "single", Request: []int{1}}
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if len(seen) != 1 || seen[0].Batch {
+			t.Errorf("expected one non-batch call, got: %d", len(seen))
+		}
+	})
+}
+
+func TestParallelBatchTransportCritical(t *testing.T) {
+	fastDone := make(chan struct{})
+	slowCancelled := make(chan struct{})
+	inner := &MockTransport{
+		SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			req := input.Requests[0]
+			reply := func(resp *JSONRPCResponse) (*SendRequestOutput, error) {
+				return &SendRequestOutput{Responses: []*JSONRPCResponse{resp}}, nil
+			}
+			switch req.Method {
+			case "fast":
+				defer close(fastDone)
+				return reply(&JSONRPCResponse{Version: "2.0", ID: req.ID, Result: json.RawMessage(`"fast"`)})
+			case "critical":
+				// Fails only after the fast result has been received
+				<-fastDone
+				return reply(&JSONRPCResponse{Version: "2.0", ID: req.ID, Error: &JSONRPCError{Code: -32000, Message: "locked"}})
+			default:
+				<-ctx.Done()
+				close(slowCancelled)
+				return nil, &InvokeError{Method: req.Method, Err: ctx.Err()}
+			}
+		},
+	}
+	client := NewClient(NewParallelBatchTransport(inner))
+
+	critical := &Invoke[[]int, string]{Name: "critical", Request: []int{1}}
+	fast := &Invoke[[]int, string]{Name: "fast", Request: []int{2}}
+	slow := &Invoke[[]int, string]{Name: "slow", Request: []int{3}}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- client.InvokeBatch(context.Background(), []MethodCaller{AsCritical(critical), fast, slow})
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the batch to return once the critical request failed")
+	}
+
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Method != "critical" {
+		t.Errorf("expected the critical error, got: %v", err)
+	}
+	var abortedErr *BatchAbortedError
+	if !errors.As(err, &abortedErr) || abortedErr.Method != "slow" || abortedErr.Critical != "critical" {
+		t.Errorf("expected slow to be aborted, got: %v", err)
+	}
+	if fast.Response != "fast" {
+		t.Errorf("expected the received response: fast, got: %s", fast.Response)
+	}
+	select {
+	case <-slowCancelled:
+	case <-time.After(time.Second):
+		t.Error("expected the slow request to be cancelled")
+	}
+}
+
+func TestParallelBatchTransportNonCriticalFailure(t *testing.T) {
+	inner := &MockTransport{
+		SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			req := input.Requests[0]
+			if req.Method == "broken" {
+				return nil, &InvokeError{Method: req.Method, Err: errors.New("reset")}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return &SendRequestOutput{Responses: []*JSONRPCResponse{
+				{Version: "2.0", ID: req.ID, Result: json.RawMessage(`"ok"`)},
+			}}, nil
+		},
+	}
+	client := NewClient(NewParallelBatchTransport(inner))
+
+	ok := &Invoke[[]int, string]{Name: "ok", Request: []int{1}}
+	err := client.InvokeBatch(context.Background(), []MethodCaller{&Invoke[[]int, string]{Name: "broken", Request: []int{2}}, AsCritical(ok)})
+
+	var abortedErr *BatchAbortedError
+	if errors.As(err, &abortedErr) {
+		t.Errorf("expected no abort for a non-critical failure, got: %v", err)
+	}
+	if ok.Response != "ok" {
+		t.Errorf("expected response: ok, got: %s", ok.Response)
+	}
+}
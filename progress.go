@@ -0,0 +1,137 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"sync"
+)
+
+// ProgressFunc is called by InvokeBatchProgress when the invoke at index completes, with the
+// error reported for it or nil once its response has been decoded into it
+type ProgressFunc func(index int, err error)
+
+// progressKey is the context key for the batch in progress, used by DeliverResponse
+type progressKey struct{}
+
+// InvokeBatchProgress calls multiple methods in a batch like InvokeBatch, calling onDone as
+// each invoke completes so that callers can react to responses before the whole batch is
+// done, e.g. to update a UI. onDone is called once for each invoke that expects a response,
+// never concurrently, and never for notifications. It returns the same aggregate error as
+// InvokeBatch.
+//
+// Responses are only reported one by one when the transport hands them over with
+// DeliverResponse as they arrive, as ParallelBatchTransport does. Otherwise every invoke
+// completes after the transport returns, in request order. If the batch fails as a whole,
+// onDone is not called for the invokes that had not completed.
+func (c *Client) InvokeBatchProgress(ctx context.Context, reqs []MethodCaller, onDone ProgressFunc) error {
+	_, err := c.invokeBatch(ctx, reqs, onDone)
+	return err
+}
+
+// DeliverResponse hands a response of the batch sent with ctx and input to
+// InvokeBatchProgress as soon as it arrives, for transports that receive batch responses one
+// at a time. The invokes it answers complete at once. The response must still be returned in
+// SendRequestOutput. It does nothing unless ctx and input are those of the SendRequest call
+// made by an InvokeBatchProgress call that has not yet returned.
+func DeliverResponse(ctx context.Context, input *SendRequestInput, resp *JSONRPCResponse) {
+	if p, ok := ctx.Value(progressKey{}).(*batchProgress); ok && p != nil && resp != nil {
+		p.deliver(input, resp)
+	}
+}
+
+// withoutProgress returns ctx without the batch in progress, for calls whose responses are
+// not returned to the client, such as shadow or hedged requests
+func withoutProgress(ctx context.Context) context.Context {
+	if ctx.Value(progressKey{}) == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, progressKey{}, (*batchProgress)(nil))
+}
+
+// batchProgress records which invokes of a batch have completed and the error of each
+type batchProgress struct {
+	client   *Client
+	reqs     []MethodCaller
+	requests []*JSONRPCRequest
+	primary  []int
+	onDone   ProgressFunc
+	// input is the input of the SendRequest call whose responses may be delivered
+	input *SendRequestInput
+
+	mu     sync.Mutex
+	closed bool
+	done   []bool
+	errs   []error
+}
+
+func newBatchProgress(c *Client, reqs []MethodCaller, requests []*JSONRPCRequest, primary []int, onDone ProgressFunc, input *SendRequestInput) *batchProgress {
+	return &batchProgress{
+		client:   c,
+		reqs:     reqs,
+		requests: requests,
+		primary:  primary,
+		onDone:   onDone,
+		input:    input,
+		done:     make([]bool, len(requests)),
+		errs:     make([]error, len(requests)),
+	}
+}
+
+// deliver completes every invoke answered by resp, including calls collapsed by dedupeBatch.
+// Responses to any other input are ignored.
+func (p *batchProgress) deliver(input *SendRequestInput, resp *JSONRPCResponse) {
+	if input != p.input {
+		return
+	}
+	key := NewNullID().String()
+	if resp.ID != nil {
+		key = resp.ID.String()
+	}
+	for i, request := range p.requests {
+		if request.ID == nil || request.ID.IsNotification() {
+			continue
+		}
+		if p.requests[p.primary[i]].ID.String() == key {
+			p.complete(i, resp)
+		}
+	}
+}
+
+// complete decodes resp into the invoke at index i, or reports MissingResponseError if resp
+// is nil. An invoke that has already completed is left as it is, and nothing is completed
+// once the progress is closed.
+func (p *batchProgress) complete(i int, resp *JSONRPCResponse) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed || p.done[i] {
+		return
+	}
+	p.done[i] = true
+
+	request := p.requests[i]
+	var err error
+	if resp == nil {
+		err = tagError(&MissingResponseError{Method: request.Method, ID: request.ID}, request.Tag)
+	} else if err = p.client.processResponse(p.reqs[i], request, resp); err != nil {
+		err = tagError(stampRequestID(err, request.ID), request.Tag)
+	}
+	p.errs[i] = err
+
+	if p.onDone != nil {
+		p.onDone(i, err)
+	}
+}
+
+// close stops further deliveries, which would otherwise write to the invokes after the
+// batch has returned, and joins the errors of the completed invokes in request order
+func (p *batchProgress) close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	var errs []error
+	for _, err := range p.errs {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return joinErrors(errs)
+}
@@ -0,0 +1,166 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestInvokeBatchProgress(t *testing.T) {
+	t.Run("reports each invoke as its response arrives", func(t *testing.T) {
+		release := make(chan struct{})
+		inner := &MockTransport{
+			SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+				req := input.Requests[0]
+				// slow is only answered once fast and broken have been reported
+				if req.Method == "slow" {
+					select {
+					case <-release:
+					case <-time.After(time.Second):
+						t.Errorf("expected fast and broken to be reported before slow was answered")
+					}
+				}
+				resp := &JSONRPCResponse{Version: "2.0", ID: req.ID, Result: json.RawMessage(`"` + req.Method + `"`)}
+				if req.Method == "broken" {
+					resp = &JSONRPCResponse{Version: "2.0", ID: req.ID, Error: &JSONRPCError{Code: -32000, Message: "broken"}}
+				}
+				return &SendRequestOutput{Responses: []*JSONRPCResponse{resp}}, nil
+			},
+		}
+		client := NewClient(NewParallelBatchTransport(inner))
+
+		slow := &Invoke[[]int, string]{Name: "slow", Request: []int{1}}
+		fast := &Invoke[[]int, string]{Name: "fast", Request: []int{2}}
+		broken := &Invoke[[]int, string]{Name: "broken", Request: []int{3}}
+		var order []int
+		var brokenErr error
+		err := client.InvokeBatchProgress(context.Background(), []MethodCaller{slow, fast, broken}, func(index int, err error) {
+			order = append(order, index)
+			switch index {
+			case 1:
+				if fast.Response != "fast" {
+					t.Errorf("expected fast to be decoded when reported, got: %q", fast.Response)
+				}
+			case 2:
+				brokenErr = err
+			}
+			if len(order) == 2 {
+				close(release)
+			}
+		})
+
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) || rpcErr.Method != "broken" {
+			t.Fatalf("expected aggregate error for broken, got: %v", err)
+		}
+		if !errors.As(brokenErr, &rpcErr) {
+			t.Errorf("expected error type for broken: *RPCError, got: %T", brokenErr)
+		}
+		if len(order) != 3 || order[2] != 0 {
+			t.Errorf("expected slow to be reported last, got order: %v", order)
+		}
+		if slow.Response != "slow" {
+			t.Errorf("expected response: slow, got: %s", slow.Response)
+		}
+	})
+
+	t.Run("reports in request order without incremental delivery", func(t *testing.T) {
+		client := NewClient(&MockTransport{
+			SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+				// The second call goes unanswered
+				return &SendRequestOutput{Responses: []*JSONRPCResponse{
+					{Version: "2.0", ID: input.Requests[0].ID, Result: json.RawMessage(`1`)},
+				}}, nil
+			},
+		})
+
+		first := &Invoke[[]int, int]{Name: "first", Request: []int{1}}
+		second := &Invoke[[]int, int]{Name: "second", Request: []int{2}}
+		notify := AsNotification(&Invoke[[]int, int]{Name: "notify", Request: []int{3}})
+		var order []int
+		var errs []error
+		err := client.InvokeBatchProgress(context.Background(), []MethodCaller{first, second, notify}, func(index int, err error) {
+			order = append(order, index)
+			errs = append(errs, err)
+		})
+
+		var missingErr *MissingResponseError
+		if !errors.As(err, &missingErr) || missingErr.Method != "second" {
+			t.Fatalf("expected aggregate MissingResponseError for second, got: %v", err)
+		}
+		if len(order) != 2 || order[0] != 0 || order[1] != 1 {
+			t.Fatalf("expected invokes 0 and 1 to be reported in order, got: %v", order)
+		}
+		if errs[0] != nil || !errors.As(errs[1], &missingErr) {
+			t.Errorf("expected errors: nil and MissingResponseError, got: %v and %v", errs[0], errs[1])
+		}
+		if first.Response != 1 {
+			t.Errorf("expected response: 1, got: %d", first.Response)
+		}
+	})
+
+	t.Run("shadow responses are not delivered", func(t *testing.T) {
+		reply := func(result string) *MockTransport {
+			return &MockTransport{
+				SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+					output := &SendRequestOutput{}
+					for _, req := range input.Requests {
+						output.Responses = append(output.Responses, &JSONRPCResponse{Version: "2.0", ID: req.ID, Result: json.RawMessage(`"` + result + `"`)})
+					}
+					return output, nil
+				},
+			}
+		}
+		shadowDone := make(chan struct{})
+		primary := reply("primary")
+		primaryFunc := primary.SendRequestFunc
+		// The primary answers only once the shadow has answered every request
+		primary.SendRequestFunc = func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			<-shadowDone
+			return primaryFunc(ctx, input)
+		}
+		tee := NewTeeTransport(primary, NewParallelBatchTransport(reply("shadow")), func(*SendRequestOutput, error) {
+			close(shadowDone)
+		})
+		client := NewClient(tee)
+
+		a := &Invoke[[]int, string]{Name: "a", Request: []int{1}}
+		b := &Invoke[[]int, string]{Name: "b", Request: []int{2}}
+		if err := client.InvokeBatchProgress(context.Background(), []MethodCaller{a, b}, func(int, error) {}); err != nil {
+			t.Fatalf("InvokeBatchProgress error: %v", err)
+		}
+		if a.Response != "primary" || b.Response != "primary" {
+			t.Errorf("expected responses from the primary, got: %s and %s", a.Response, b.Response)
+		}
+	})
+
+	t.Run("deliveries after return are ignored", func(t *testing.T) {
+		var late func()
+		client := NewClient(&MockTransport{
+			SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+				resp := &JSONRPCResponse{Version: "2.0", ID: input.Requests[0].ID, Result: json.RawMessage(`"ok"`)}
+				late = func() {
+					DeliverResponse(ctx, input, &JSONRPCResponse{Version: "2.0", ID: resp.ID, Result: json.RawMessage(`"late"`)})
+				}
+				return &SendRequestOutput{Responses: []*JSONRPCResponse{resp}}, nil
+			},
+		})
+
+		calls := 0
+		a := &Invoke[[]int, string]{Name: "a", Request: []int{1}}
+		if err := client.InvokeBatchProgress(context.Background(), []MethodCaller{a}, func(int, error) { calls++ }); err != nil {
+			t.Fatalf("InvokeBatchProgress error: %v", err)
+		}
+		late()
+		if a.Response != "ok" || calls != 1 {
+			t.Errorf("expected response ok reported once, got: %s reported %d times", a.Response, calls)
+		}
+	})
+
+	t.Run("DeliverResponse without a batch in progress", func(t *testing.T) {
+		// Must not panic
+		DeliverResponse(context.Background(), &SendRequestInput{}, &JSONRPCResponse{ID: NewID(1)})
+	})
+}
@@ -0,0 +1,63 @@
+package jsonrpc_client
+
+import "encoding/json"
+
+// RawInvoke wraps an already built JSONRPCRequest so it can be sent through a Client,
+// e.g. when proxying requests. Its ID and params are forwarded as-is; a nil ID is still
+// assigned by the client, and the client's version, empty params and request transform
+// settings still apply.
+type RawInvoke[Tout any] struct {
+	Request  *JSONRPCRequest
+	Response Tout
+}
+
+// FromRequest creates a RawInvoke that forwards req and decodes its result into Tout
+func FromRequest[Tout any](req *JSONRPCRequest) *RawInvoke[Tout] {
+	return &RawInvoke[Tout]{Request: req}
+}
+
+// JSONRPCRequest returns a copy of the wrapped request, so the caller's request is not
+// modified by ID assignment or transforms
+func (r *RawInvoke[Tout]) JSONRPCRequest() *JSONRPCRequest {
+	request := *r.Request
+	return &request
+}
+
+// resetResponse clears the result of a previous call
+func (r *RawInvoke[Tout]) resetResponse() {
+	var zero Tout
+	r.Response = zero
+}
+
+// Unmarshal decodes the result of the response into Response
+func (r *RawInvoke[Tout]) Unmarshal(resp *JSONRPCResponse) error {
+	if resp.missingResult() {
+		return &EmptyResultError{Method: r.Request.Method}
+	}
+	if err := resp.decodeResult(&r.Response); err != nil {
+		return &UnmarshalError{Method: r.Request.Method, Err: err}
+	}
+	return validateResult(r.Request.Method, &r.Response)
+}
+
+// Raw is a result type that keeps the original JSON of a result next to its decoded value,
+// e.g. to forward or log the exact bytes. Use it as Tout, as in Invoke[P, Raw[T]].
+type Raw[T any] struct {
+	Value T
+	JSON  json.RawMessage
+}
+
+// UnmarshalJSON stores a copy of data and decodes it into Value
+func (r *Raw[T]) UnmarshalJSON(data []byte) error {
+	r.JSON = append(r.JSON[:0], data...)
+	return json.Unmarshal(data, &r.Value)
+}
+
+// MarshalJSON returns the original JSON if present, so that it is forwarded unchanged,
+// and the encoding of Value otherwise
+func (r Raw[T]) MarshalJSON() ([]byte, error) {
+	if r.JSON != nil {
+		return r.JSON, nil
+	}
+	return json.Marshal(r.Value)
+}
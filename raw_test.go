@@ -0,0 +1,141 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFromRequest(t *testing.T) {
+	var sent *JSONRPCRequest
+	transport := &MockTransport{
+		SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			sent = input.Requests[0]
+			if sent.ID.IsNotification() {
+				return &SendRequestOutput{}, nil
+			}
+			return &SendRequestOutput{
+				Responses: []*JSONRPCResponse{{ID: sent.ID, Result: json.RawMessage(`{"sum":3}`)}},
+			}, nil
+		},
+	}
+	client := NewClient(transport)
+
+	t.Run("forwards ID and params", func(t *testing.T) {
+		request := &JSONRPCRequest{
+			Version: "2.0",
+			ID:      NewID("upstream-42"),
+			Method:  "add",
+			Params:  json.RawMessage(`[1,2]`),
+		}
+		invoke := FromRequest[map[string]int](request)
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+
+		if !sent.ID.Equal(NewID("upstream-42")) {
+			t.Errorf("expected ID: upstream-42, got: %v", sent.ID)
+		}
+		if params, _ := json.Marshal(sent.Params); string(params) != `[1,2]` {
+			t.Errorf("expected params: [1,2], got: %s", params)
+		}
+		if invoke.Response["sum"] != 3 {
+			t.Errorf("expected sum: 3, got: %d", invoke.Response["sum"])
+		}
+	})
+
+	t.Run("nil ID is assigned without modifying the original", func(t *testing.T) {
+		request := &JSONRPCRequest{Version: "2.0", Method: "add"}
+		if err := client.Invoke(context.Background(), FromRequest[json.RawMessage](request)); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if sent.ID == nil {
+			t.Error("expected ID to be generated")
+		}
+		if request.ID != nil {
+			t.Errorf("expected original request to be unchanged, got ID: %v", request.ID)
+		}
+	})
+
+	t.Run("notification", func(t *testing.T) {
+		request := &JSONRPCRequest{Version: "2.0", ID: NewNotificationID(), Method: "log"}
+		if err := client.Invoke(context.Background(), FromRequest[json.RawMessage](request)); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+	})
+
+	t.Run("empty result", func(t *testing.T) {
+		invoke := FromRequest[int](&JSONRPCRequest{Method: "add"})
+		err := invoke.Unmarshal(&JSONRPCResponse{ID: NewID(1)})
+
+		var emptyErr *EmptyResultError
+		if !errors.As(err, &emptyErr) {
+			t.Fatalf("expected error type: *EmptyResultError, got: %T", err)
+		}
+	})
+}
+
+func TestRawResult(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+	const result = `{"name":"alice", "extra":[1,2]}`
+
+	transport := &MockTransport{
+		SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			return &SendRequestOutput{
+				Responses: []*JSONRPCResponse{{ID: input.Requests[0].ID, Result: json.RawMessage(result)}},
+			}, nil
+		},
+	}
+	client := NewClient(transport)
+
+	t.Run("value and raw bytes", func(t *testing.T) {
+		invoke := &Invoke[[]int, Raw[user]]{Name: "getUser", Request: []int{1}}
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if invoke.Response.Value.Name != "alice" {
+			t.Errorf("expected name: alice, got: %s", invoke.Response.Value.Name)
+		}
+		if string(invoke.Response.JSON) != result {
+			t.Errorf("expected raw JSON: %s, got: %s", result, invoke.Response.JSON)
+		}
+	})
+
+	t.Run("marshal forwards original bytes", func(t *testing.T) {
+		raw := Raw[user]{Value: user{Name: "changed"}, JSON: json.RawMessage(result)}
+		data, err := json.Marshal(raw)
+		if err != nil {
+			t.Fatalf("Marshal error: %v", err)
+		}
+		var expected, got any
+		json.Unmarshal([]byte(result), &expected)
+		json.Unmarshal(data, &got)
+		if !reflect.DeepEqual(expected, got) {
+			t.Errorf("expected: %s, got: %s", result, data)
+		}
+	})
+
+	t.Run("marshal without raw bytes", func(t *testing.T) {
+		data, err := json.Marshal(Raw[user]{Value: user{Name: "bob"}})
+		if err != nil {
+			t.Fatalf("Marshal error: %v", err)
+		}
+		if string(data) != `{"name":"bob"}` {
+			t.Errorf("expected: {\"name\":\"bob\"}, got: %s", data)
+		}
+	})
+
+	t.Run("decode error", func(t *testing.T) {
+		invoke := &Invoke[[]int, Raw[[]string]]{Name: "getUser", Request: []int{1}}
+		err := client.Invoke(context.Background(), invoke)
+
+		var unmarshalErr *UnmarshalError
+		if !errors.As(err, &unmarshalErr) {
+			t.Fatalf("expected error type: *UnmarshalError, got: %T", err)
+		}
+	})
+}
@@ -0,0 +1,225 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RetryTransport resends requests that failed with a retryable error, waiting between
+// attempts. Which errors are retryable is decided by DefaultRetryable unless
+// WithRetryableFunc is set.
+type RetryTransport struct {
+	inner       Transport
+	maxAttempts int
+	backoff     func(attempt int) time.Duration
+	retryable   func(err error) bool
+	clock       Clock
+	budget      *RetryBudget
+	codes       map[int]bool
+}
+
+type RetryOption func(*RetryTransport)
+
+// WithMaxAttempts sets how many times a request is sent in total, including the first
+// attempt. The default is 3.
+func WithMaxAttempts(n int) RetryOption {
+	return func(t *RetryTransport) {
+		t.maxAttempts = max(n, 1)
+	}
+}
+
+// WithRetryBackoff sets the wait before retry number attempt (starting at 1).
+// The default doubles from 100ms up to 5s.
+func WithRetryBackoff(backoff func(attempt int) time.Duration) RetryOption {
+	return func(t *RetryTransport) {
+		t.backoff = backoff
+	}
+}
+
+// WithRetryableFunc sets the function that decides whether an error returned by the inner
+// transport is worth retrying. It receives the error as returned, so it can inspect it with
+// errors.As.
+func WithRetryableFunc(retryable func(err error) bool) RetryOption {
+	return func(t *RetryTransport) {
+		t.retryable = retryable
+	}
+}
+
+// WithRetryClock sets the clock used to wait between attempts. It is mainly useful in tests.
+func WithRetryClock(clock Clock) RetryOption {
+	return func(t *RetryTransport) {
+		t.clock = clock
+	}
+}
+
+// WithRetryBudget makes retries draw from budget, which can be shared by several transports
+// to cap retries across all of their calls. When the budget is exhausted, the error of the
+// failed attempt is returned without retrying.
+func WithRetryBudget(budget *RetryBudget) RetryOption {
+	return func(t *RetryTransport) {
+		t.budget = budget
+	}
+}
+
+// WithRetryableCodes retries a single request whose response carries a JSON-RPC error with
+// one of codes, e.g. a custom -32000 "server busy", with the same backoff, attempt limit and
+// budget as transport errors. When the attempts are used up, the last response is returned
+// as is. Batches are not retried on error codes, since the other requests in them succeeded.
+func WithRetryableCodes(codes ...int) RetryOption {
+	return func(t *RetryTransport) {
+		t.codes = make(map[int]bool, len(codes))
+		for _, code := range codes {
+			t.codes[code] = true
+		}
+	}
+}
+
+// NewRetryTransport creates a RetryTransport that sends requests through inner
+func NewRetryTransport(inner Transport, opts ...RetryOption) *RetryTransport {
+	t := &RetryTransport{
+		inner:       inner,
+		maxAttempts: 3,
+		backoff:     defaultRetryBackoff,
+		retryable:   DefaultRetryable,
+		clock:       realClock{},
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// defaultRetryBackoff doubles from 100ms and is capped at 5s
+func defaultRetryBackoff(attempt int) time.Duration {
+	const maxBackoff = 5 * time.Second
+	d := 100 * time.Millisecond
+	for i := 1; i < attempt && d < maxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, maxBackoff)
+}
+
+// DefaultRetryable reports whether err is transient: connection failures and timeouts
+// (InvokeError), and StatusCodeError with status 429 or 5xx. Other status codes and protocol
+// errors such as RPCError, MarshalError and UnmarshalError are permanent, as is an
+// InvokeError from the request mutator, which fails the same way on every attempt.
+func DefaultRetryable(err error) bool {
+	var marshalErr *MarshalError
+	if errors.As(err, &marshalErr) {
+		return false
+	}
+	var statusErr *StatusCodeError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	var invokeErr *InvokeError
+	return errors.As(err, &invokeErr) && !invokeErr.permanent
+}
+
+// retryableResponse reports whether output answers a single request with a retryable error code
+func (t *RetryTransport) retryableResponse(input *SendRequestInput, output *SendRequestOutput) bool {
+	if len(t.codes) == 0 || input.Batch || output == nil || len(output.Responses) != 1 {
+		return false
+	}
+	resp := output.Responses[0]
+	return resp != nil && resp.Error != nil && t.codes[resp.Error.Code]
+}
+
+// SendRequest sends the request, retrying retryable errors and responses with a retryable
+// error code until the attempts are used up or ctx is done. The outcome of the last attempt
+// is returned.
+func (t *RetryTransport) SendRequest(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+	if t.budget != nil {
+		t.budget.deposit()
+	}
+	for attempt := 1; ; attempt++ {
+		output, err := t.inner.SendRequest(ctx, input)
+		retry := t.retryableResponse(input, output)
+		if err != nil {
+			retry = t.retryable(err)
+		}
+		if !retry || attempt >= t.maxAttempts || ctx.Err() != nil {
+			return output, err
+		}
+		if t.budget != nil && !t.budget.withdraw() {
+			return output, err
+		}
+
+		select {
+		case <-t.clock.After(t.backoff(attempt)):
+		case <-ctx.Done():
+			return output, err
+		}
+	}
+}
+
+// RetryBudget limits retries across calls, so that under high volume retries stay a fraction
+// of the traffic instead of multiplying the load on a struggling server. It works like a
+// token bucket: each retry takes one token, and each call earns back a fraction of one, up to
+// the maximum. It is safe for concurrent use.
+type RetryBudget struct {
+	mu sync.Mutex
+	// tokens, max and ratio are in thousandths of a retry, so fractions add up exactly
+	tokens int64
+	max    int64
+	ratio  int64
+}
+
+// budgetScale is the number of budget units in one retry
+const budgetScale = 1000
+
+type RetryBudgetOption func(*RetryBudget)
+
+// WithBudgetMaxRetries sets how many tokens the budget holds at most, which is also the
+// burst of retries it allows at once. The default is 10.
+func WithBudgetMaxRetries(n int) RetryBudgetOption {
+	return func(b *RetryBudget) {
+		b.max = int64(max(n, 0)) * budgetScale
+	}
+}
+
+// WithBudgetRatio sets how much of a retry each call earns back, e.g. 0.1 allows retries
+// for about one call in ten once the burst is spent. The default is 0.1.
+func WithBudgetRatio(ratio float64) RetryBudgetOption {
+	return func(b *RetryBudget) {
+		b.ratio = int64(max(ratio, 0) * budgetScale)
+	}
+}
+
+// NewRetryBudget creates a RetryBudget that starts full
+func NewRetryBudget(opts ...RetryBudgetOption) *RetryBudget {
+	b := &RetryBudget{max: 10 * budgetScale, ratio: budgetScale / 10}
+	for _, opt := range opts {
+		opt(b)
+	}
+	b.tokens = b.max
+	return b
+}
+
+// Available returns the number of retries the budget currently allows
+func (b *RetryBudget) Available() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return int(b.tokens / budgetScale)
+}
+
+// deposit credits the budget for a call
+func (b *RetryBudget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.tokens+b.ratio, b.max)
+}
+
+// withdraw takes a token for a retry and reports whether one was available
+func (b *RetryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < budgetScale {
+		return false
+	}
+	b.tokens -= budgetScale
+	return true
+}
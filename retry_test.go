@@ -0,0 +1,343 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/yacchi/go-jsonrpc-client/testutil"
+)
+
+// sequenceTransport returns the given errors in order, then succeeds
+type sequenceTransport struct {
+	errs  []error
+	calls int
+}
+
+func (s *sequenceTransport) SendRequest(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+	s.calls++
+	if s.calls <= len(s.errs) {
+		return nil, s.errs[s.calls-1]
+	}
+	return &SendRequestOutput{Responses: []*JSONRPCResponse{
+		{Version: "2.0", ID: input.Requests[0].ID, Result: json.RawMessage(`"ok"`)},
+	}}, nil
+}
+
+func noBackoff(int) time.Duration { return 0 }
+
+func TestDefaultRetryable(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{name: "connection error", err: &InvokeError{Method: "m", Err: errors.New("connection refused")}, retryable: true},
+		{name: "timeout", err: &InvokeError{Method: "m", Err: context.DeadlineExceeded}, retryable: true},
+		{name: "429", err: &StatusCodeError{Method: "m", StatusCode: http.StatusTooManyRequests}, retryable: true},
+		{name: "503", err: &StatusCodeError{Method: "m", StatusCode: http.StatusServiceUnavailable}, retryable: true},
+		{name: "400", err: &StatusCodeError{Method: "m", StatusCode: http.StatusBadRequest}, retryable: false},
+		{name: "404", err: &StatusCodeError{Method: "m", StatusCode: http.StatusNotFound}, retryable: false},
+		{name: "invalid params", err: &RPCError{Method: "m", Code: -32602}, retryable: false},
+		{name: "marshal error", err: &MarshalError{Method: "m", Err: errors.New("bad")}, retryable: false},
+		{name: "wrapped marshal error", err: &InvokeError{Method: "m", Err: &MarshalError{Method: "m", Err: errors.New("bad")}}, retryable: false},
+		{name: "mutator error", err: &InvokeError{Method: "m", Err: errors.New("no credentials"), permanent: true}, retryable: false},
+		{name: "unmarshal error", err: &UnmarshalError{Method: "m", Err: errors.New("bad")}, retryable: false},
+		{name: "invalid request", err: &InvalidRequestError{Message: "bad"}, retryable: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultRetryable(tt.err); got != tt.retryable {
+				t.Errorf("expected retryable: %v, got: %v", tt.retryable, got)
+			}
+		})
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+	unavailable := &StatusCodeError{Method: "test", StatusCode: http.StatusServiceUnavailable}
+
+	t.Run("mutator errors are not retried", func(t *testing.T) {
+		mutations := 0
+		inner := NewHTTPTransport("http://127.0.0.1:0", WithRequestMutator(func(req *http.Request) error {
+			mutations++
+			return errors.New("no credentials")
+		}))
+		client := NewClient(NewRetryTransport(inner, WithRetryBackoff(noBackoff)))
+
+		err := client.Invoke(context.Background(), &Invoke[[]int, string]{Name: "test", Request: []int{1}})
+		var invokeErr *InvokeError
+		if !errors.As(err, &invokeErr) {
+			t.Fatalf("expected error type: *InvokeError, got: %T", err)
+		}
+		if mutations != 1 {
+			t.Errorf("expected 1 attempt, got: %d", mutations)
+		}
+	})
+
+	t.Run("retries until success", func(t *testing.T) {
+		inner := &sequenceTransport{errs: []error{unavailable, unavailable}}
+		client := NewClient(NewRetryTransport(inner, WithRetryBackoff(noBackoff)))
+
+		invoke := &Invoke[[]int, string]{Name: "test", Request: []int{1}}
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if inner.calls != 3 {
+			t.Errorf("expected 3 attempts, got: %d", inner.calls)
+		}
+		if invoke.Response != "ok" {
+			t.Errorf("expected response: ok, got: %s", invoke.Response)
+		}
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		inner := &sequenceTransport{errs: []error{unavailable, unavailable, unavailable}}
+		transport := NewRetryTransport(inner, WithMaxAttempts(2), WithRetryBackoff(noBackoff))
+
+		_, err := transport.SendRequest(context.Background(), &SendRequestInput{
+			Requests: []*JSONRPCRequest{{ID: NewID(1), Method: "test"}},
+		})
+		if !errors.Is(err, unavailable) {
+			t.Errorf("expected the last error, got: %v", err)
+		}
+		if inner.calls != 2 {
+			t.Errorf("expected 2 attempts, got: %d", inner.calls)
+		}
+	})
+
+	t.Run("permanent errors are not retried", func(t *testing.T) {
+		inner := &sequenceTransport{errs: []error{&StatusCodeError{Method: "test", StatusCode: http.StatusBadRequest}}}
+		transport := NewRetryTransport(inner, WithRetryBackoff(noBackoff))
+
+		_, err := transport.SendRequest(context.Background(), &SendRequestInput{
+			Requests: []*JSONRPCRequest{{ID: NewID(1), Method: "test"}},
+		})
+
+		var statusErr *StatusCodeError
+		if !errors.As(err, &statusErr) {
+			t.Fatalf("expected error type: *StatusCodeError, got: %T", err)
+		}
+		if inner.calls != 1 {
+			t.Errorf("expected 1 attempt, got: %d", inner.calls)
+		}
+	})
+
+	t.Run("custom retryable func", func(t *testing.T) {
+		busy := &RPCError{Method: "test", Code: -32000, Message: "busy"}
+		inner := &sequenceTransport{errs: []error{busy, unavailable}}
+
+		var seen []error
+		transport := NewRetryTransport(inner, WithRetryBackoff(noBackoff), WithRetryableFunc(func(err error) bool {
+			seen = append(seen, err)
+			var rpcErr *RPCError
+			return errors.As(err, &rpcErr) && rpcErr.Code == -32000
+		}))
+
+		_, err := transport.SendRequest(context.Background(), &SendRequestInput{
+			Requests: []*JSONRPCRequest{{ID: NewID(1), Method: "test"}},
+		})
+		if !errors.Is(err, unavailable) {
+			t.Errorf("expected the 503 not to be retried, got: %v", err)
+		}
+		if inner.calls != 2 || len(seen) != 2 {
+			t.Errorf("expected 2 attempts and 2 classifications, got: %d and %d", inner.calls, len(seen))
+		}
+	})
+
+	t.Run("waits for backoff", func(t *testing.T) {
+		clock := testutil.NewFakeClock(time.Unix(0, 0))
+		inner := &sequenceTransport{errs: []error{unavailable}}
+		transport := NewRetryTransport(inner, WithRetryClock(clock))
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := transport.SendRequest(context.Background(), &SendRequestInput{
+				Requests: []*JSONRPCRequest{{ID: NewID(1), Method: "test"}},
+			})
+			done <- err
+		}()
+
+		for clock.Waiters() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		clock.Advance(defaultRetryBackoff(1))
+		if err := <-done; err != nil {
+			t.Errorf("expected success after backoff, got: %v", err)
+		}
+	})
+
+	t.Run("context cancelled during backoff", func(t *testing.T) {
+		inner := &sequenceTransport{errs: []error{unavailable, unavailable}}
+		transport := NewRetryTransport(inner, WithRetryBackoff(func(int) time.Duration { return time.Hour }))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := transport.SendRequest(ctx, &SendRequestInput{
+			Requests: []*JSONRPCRequest{{ID: NewID(1), Method: "test"}},
+		})
+		if !errors.Is(err, unavailable) {
+			t.Errorf("expected the last error, got: %v", err)
+		}
+		if inner.calls != 1 {
+			t.Errorf("expected 1 attempt, got: %d", inner.calls)
+		}
+	})
+}
+
+func TestDefaultRetryBackoff(t *testing.T) {
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond}
+	for i, want := range expected {
+		if got := defaultRetryBackoff(i + 1); got != want {
+			t.Errorf("expected backoff %d: %s, got: %s", i+1, want, got)
+		}
+	}
+	if got := defaultRetryBackoff(100); got != 5*time.Second {
+		t.Errorf("expected backoff to be capped at 5s, got: %s", got)
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	unavailable := &StatusCodeError{Method: "test", StatusCode: http.StatusServiceUnavailable}
+	input := &SendRequestInput{Requests: []*JSONRPCRequest{{ID: NewID(1), Method: "test"}}}
+
+	t.Run("exhausted budget stops retries", func(t *testing.T) {
+		budget := NewRetryBudget(WithBudgetMaxRetries(2), WithBudgetRatio(0))
+		inner := &sequenceTransport{errs: []error{unavailable, unavailable, unavailable, unavailable, unavailable}}
+		transport := NewRetryTransport(inner, WithMaxAttempts(5), WithRetryBackoff(noBackoff), WithRetryBudget(budget))
+
+		_, err := transport.SendRequest(context.Background(), input)
+		if !errors.Is(err, unavailable) {
+			t.Errorf("expected the original error, got: %v", err)
+		}
+		if inner.calls != 3 {
+			t.Errorf("expected 1 attempt and 2 retries, got: %d attempts", inner.calls)
+		}
+		if budget.Available() != 0 {
+			t.Errorf("expected an empty budget, got: %d", budget.Available())
+		}
+	})
+
+	t.Run("shared across transports", func(t *testing.T) {
+		budget := NewRetryBudget(WithBudgetMaxRetries(1), WithBudgetRatio(0))
+		first := &sequenceTransport{errs: []error{unavailable}}
+		second := &sequenceTransport{errs: []error{unavailable}}
+
+		if _, err := NewRetryTransport(first, WithRetryBackoff(noBackoff), WithRetryBudget(budget)).SendRequest(context.Background(), input); err != nil {
+			t.Errorf("expected the first call to be retried, got: %v", err)
+		}
+		_, err := NewRetryTransport(second, WithRetryBackoff(noBackoff), WithRetryBudget(budget)).SendRequest(context.Background(), input)
+		if !errors.Is(err, unavailable) || second.calls != 1 {
+			t.Errorf("expected the second call not to be retried, got: %v after %d attempts", err, second.calls)
+		}
+	})
+
+	t.Run("calls earn the budget back", func(t *testing.T) {
+		budget := NewRetryBudget(WithBudgetMaxRetries(1), WithBudgetRatio(0.1))
+		if !budget.withdraw() || budget.withdraw() {
+			t.Fatal("expected exactly one retry from a full budget")
+		}
+		for i := 0; i < 9; i++ {
+			budget.deposit()
+		}
+		if budget.Available() != 0 {
+			t.Errorf("expected no retry after 9 calls, got: %d", budget.Available())
+		}
+		budget.deposit()
+		if budget.Available() != 1 {
+			t.Errorf("expected a retry after 10 calls, got: %d", budget.Available())
+		}
+		budget.deposit()
+		if budget.Available() != 1 {
+			t.Errorf("expected the budget to be capped at 1, got: %d", budget.Available())
+		}
+	})
+}
+
+// busyTransport answers with the busy error code a number of times, then succeeds
+type busyTransport struct {
+	busy  int
+	calls int
+}
+
+func (b *busyTransport) SendRequest(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+	b.calls++
+	output := &SendRequestOutput{}
+	for _, req := range input.Requests {
+		resp := &JSONRPCResponse{Version: "2.0", ID: req.ID, Result: json.RawMessage(`"ok"`)}
+		if b.calls <= b.busy {
+			resp = &JSONRPCResponse{Version: "2.0", ID: req.ID, Error: &JSONRPCError{Code: -32000, Message: "server busy"}}
+		}
+		output.Responses = append(output.Responses, resp)
+	}
+	return output, nil
+}
+
+func TestRetryTransportRetryableCodes(t *testing.T) {
+	t.Run("busy then success", func(t *testing.T) {
+		inner := &busyTransport{busy: 2}
+		client := NewClient(NewRetryTransport(inner, WithRetryBackoff(noBackoff), WithRetryableCodes(-32000)))
+
+		invoke := &Invoke[[]int, string]{Name: "test", Request: []int{1}}
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if inner.calls != 3 || invoke.Response != "ok" {
+			t.Errorf("expected success on the 3rd attempt, got: %d attempts and %q", inner.calls, invoke.Response)
+		}
+	})
+
+	t.Run("attempts used up", func(t *testing.T) {
+		inner := &busyTransport{busy: 5}
+		client := NewClient(NewRetryTransport(inner, WithMaxAttempts(2), WithRetryBackoff(noBackoff), WithRetryableCodes(-32000)))
+
+		err := client.Invoke(context.Background(), &Invoke[[]int, string]{Name: "test", Request: []int{1}})
+
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) || rpcErr.Code != -32000 {
+			t.Errorf("expected the busy RPCError, got: %v", err)
+		}
+		if inner.calls != 2 {
+			t.Errorf("expected 2 attempts, got: %d", inner.calls)
+		}
+	})
+
+	t.Run("other codes are not retried", func(t *testing.T) {
+		inner := &busyTransport{busy: 1}
+		client := NewClient(NewRetryTransport(inner, WithRetryBackoff(noBackoff), WithRetryableCodes(-32001)))
+
+		if err := client.Invoke(context.Background(), &Invoke[[]int, string]{Name: "test", Request: []int{1}}); err == nil {
+			t.Error("expected the busy error to be returned")
+		}
+		if inner.calls != 1 {
+			t.Errorf("expected 1 attempt, got: %d", inner.calls)
+		}
+	})
+
+	t.Run("batches are not retried", func(t *testing.T) {
+		inner := &busyTransport{busy: 1}
+		client := NewClient(NewRetryTransport(inner, WithRetryBackoff(noBackoff), WithRetryableCodes(-32000)))
+
+		_ = client.InvokeBatch(context.Background(), []MethodCaller{&Invoke[[]int, string]{Name: "test", Request: []int{1}}})
+		if inner.calls != 1 {
+			t.Errorf("expected 1 attempt, got: %d", inner.calls)
+		}
+	})
+
+	t.Run("budget applies", func(t *testing.T) {
+		inner := &busyTransport{busy: 1}
+		budget := NewRetryBudget(WithBudgetMaxRetries(0))
+		client := NewClient(NewRetryTransport(inner, WithRetryBackoff(noBackoff), WithRetryableCodes(-32000), WithRetryBudget(budget)))
+
+		if err := client.Invoke(context.Background(), &Invoke[[]int, string]{Name: "test", Request: []int{1}}); err == nil {
+			t.Error("expected the busy error without a budget to retry")
+		}
+		if inner.calls != 1 {
+			t.Errorf("expected 1 attempt, got: %d", inner.calls)
+		}
+	})
+}
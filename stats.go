@@ -0,0 +1,49 @@
+package jsonrpc_client
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// TransportStats is a snapshot of the counters of an HTTPTransport
+type TransportStats struct {
+	// Requests is the number of SendRequest calls; a batch counts as one
+	Requests uint64
+	// Errors is the number of SendRequest calls that returned an error
+	Errors uint64
+	// BytesSent is the total size of the request bodies sent
+	BytesSent uint64
+	// BytesReceived is the total size of the response bodies read, including drained bytes
+	BytesReceived uint64
+}
+
+// transportCounters holds the live counters behind TransportStats
+type transportCounters struct {
+	requests      atomic.Uint64
+	errors        atomic.Uint64
+	bytesSent     atomic.Uint64
+	bytesReceived atomic.Uint64
+}
+
+// Stats returns a snapshot of the transport's counters. It is safe to call concurrently
+// with SendRequest.
+func (t *HTTPTransport) Stats() TransportStats {
+	return TransportStats{
+		Requests:      t.stats.requests.Load(),
+		Errors:        t.stats.errors.Load(),
+		BytesSent:     t.stats.bytesSent.Load(),
+		BytesReceived: t.stats.bytesReceived.Load(),
+	}
+}
+
+// countingReadCloser counts the bytes read from a response body
+type countingReadCloser struct {
+	io.ReadCloser
+	n *atomic.Uint64
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n.Add(uint64(n))
+	return n, err
+}
@@ -0,0 +1,84 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestHTTPTransportStats(t *testing.T) {
+	const responseBody = `{"jsonrpc":"2.0","id":1,"result":"ok"}`
+	var requestSize atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requestSize.Store(int64(len(body)))
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(responseBody))
+	}))
+	defer server.Close()
+
+	input := func() *SendRequestInput {
+		return &SendRequestInput{
+			Requests: []*JSONRPCRequest{{Version: "2.0", ID: NewID(1), Method: "test.method"}},
+		}
+	}
+
+	t.Run("counts requests and bytes", func(t *testing.T) {
+		transport := NewHTTPTransport(server.URL)
+		if _, err := transport.SendRequest(context.Background(), input()); err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+
+		stats := transport.Stats()
+		if stats.Requests != 1 || stats.Errors != 0 {
+			t.Errorf("expected 1 request and 0 errors, got: %+v", stats)
+		}
+		if stats.BytesSent != uint64(requestSize.Load()) {
+			t.Errorf("expected bytes sent: %d, got: %d", requestSize.Load(), stats.BytesSent)
+		}
+		if stats.BytesReceived != uint64(len(responseBody)) {
+			t.Errorf("expected bytes received: %d, got: %d", len(responseBody), stats.BytesReceived)
+		}
+	})
+
+	t.Run("counts errors", func(t *testing.T) {
+		transport := NewHTTPTransport(server.URL + "/fail")
+		if _, err := transport.SendRequest(context.Background(), input()); err == nil {
+			t.Fatal("expected an error")
+		}
+		if _, err := transport.SendRequest(context.Background(), &SendRequestInput{}); err == nil {
+			t.Fatal("expected an error")
+		}
+
+		stats := transport.Stats()
+		if stats.Requests != 2 || stats.Errors != 2 {
+			t.Errorf("expected 2 requests and 2 errors, got: %+v", stats)
+		}
+	})
+
+	t.Run("concurrent use", func(t *testing.T) {
+		transport := NewHTTPTransport(server.URL)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				transport.SendRequest(context.Background(), input())
+				transport.Stats()
+			}()
+		}
+		wg.Wait()
+
+		if stats := transport.Stats(); stats.Requests != 10 {
+			t.Errorf("expected 10 requests, got: %d", stats.Requests)
+		}
+	})
+}
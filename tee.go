@@ -0,0 +1,149 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// DefaultShadowTimeout bounds each shadow call of a TeeTransport unless WithShadowTimeout is given
+const DefaultShadowTimeout = 30 * time.Second
+
+// TeeTransport sends every request to a primary transport and mirrors it to a shadow
+// transport. Only the primary's result is returned; the shadow call runs asynchronously
+// and cannot affect the primary's latency or error.
+type TeeTransport struct {
+	primary  Transport
+	shadow   Transport
+	onShadow func(output *SendRequestOutput, err error)
+
+	timeout time.Duration
+	// slots limits the shadow calls in flight; nil means no limit
+	slots chan struct{}
+}
+
+// TeeOption is a function that configures a TeeTransport
+type TeeOption func(*TeeTransport)
+
+// WithShadowTimeout bounds each shadow call to timeout, so that a shadow that hangs does not
+// hold on to its goroutine. The default is DefaultShadowTimeout. A timeout of 0 or less
+// removes the bound.
+func WithShadowTimeout(timeout time.Duration) TeeOption {
+	return func(t *TeeTransport) {
+		t.timeout = timeout
+	}
+}
+
+// WithMaxShadows limits the number of shadow calls in flight to n. While the limit is
+// reached, requests are not mirrored, and onShadow receives ShadowDroppedError instead.
+func WithMaxShadows(n int) TeeOption {
+	return func(t *TeeTransport) {
+		if n > 0 {
+			t.slots = make(chan struct{}, n)
+		}
+	}
+}
+
+// NewTeeTransport creates a TeeTransport. onShadow, if non-nil, receives the shadow's result.
+func NewTeeTransport(primary, shadow Transport, onShadow func(output *SendRequestOutput, err error), opts ...TeeOption) *TeeTransport {
+	t := &TeeTransport{
+		primary:  primary,
+		shadow:   shadow,
+		onShadow: onShadow,
+		timeout:  DefaultShadowTimeout,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// SendRequest sends the request to the primary and, in the background, to the shadow
+func (t *TeeTransport) SendRequest(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+	if !t.acquire() {
+		if t.onShadow != nil {
+			t.onShadow(nil, &ShadowDroppedError{Method: input.Requests[0].Method, Limit: cap(t.slots)})
+		}
+		return t.primary.SendRequest(ctx, input)
+	}
+
+	// The shadow outlives the call, so it gets its own copy of the requests that the caller
+	// cannot change once the call has returned
+	shadowInput, err := snapshotInput(input)
+	if err != nil {
+		t.release()
+		if t.onShadow != nil {
+			t.onShadow(nil, err)
+		}
+		return t.primary.SendRequest(ctx, input)
+	}
+
+	// The shadow keeps the caller's context values but is not cancelled when the
+	// primary call returns. Its responses must not complete the caller's invokes.
+	shadowCtx := withoutProgress(context.WithoutCancel(ctx))
+	go func() {
+		defer t.release()
+		ctx := shadowCtx
+		if t.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, t.timeout)
+			defer cancel()
+		}
+		output, err := t.shadow.SendRequest(ctx, shadowInput)
+		if t.onShadow != nil {
+			t.onShadow(output, err)
+		}
+	}()
+
+	return t.primary.SendRequest(ctx, input)
+}
+
+// snapshotInput copies input with the params and meta of each request encoded to JSON, so
+// that later changes by the caller do not affect the copy
+func snapshotInput(input *SendRequestInput) (*SendRequestInput, error) {
+	snapshot := &SendRequestInput{Requests: make([]*JSONRPCRequest, len(input.Requests)), Batch: input.Batch}
+	for i, request := range input.Requests {
+		copied := *request
+		var err error
+		if copied.Params, err = snapshotValue(request.Params); err != nil {
+			return nil, &MarshalError{Method: request.Method, Err: err}
+		}
+		if copied.Meta, err = snapshotValue(request.Meta); err != nil {
+			return nil, &MarshalError{Method: request.Method, Err: err}
+		}
+		snapshot.Requests[i] = &copied
+	}
+	return snapshot, nil
+}
+
+// snapshotValue encodes v to JSON, leaving nil as it is so that it is still omitted
+func snapshotValue(v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(data), nil
+}
+
+// acquire takes a shadow slot, reporting false if all of them are in use
+func (t *TeeTransport) acquire() bool {
+	if t.slots == nil {
+		return true
+	}
+	select {
+	case t.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// release returns a slot taken by acquire
+func (t *TeeTransport) release() {
+	if t.slots != nil {
+		<-t.slots
+	}
+}
@@ -0,0 +1,196 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTeeTransport(t *testing.T) {
+	respond := func(result string) func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+		return func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			return &SendRequestOutput{
+				Responses: []*JSONRPCResponse{{ID: input.Requests[0].ID, Result: json.RawMessage(`"` + result + `"`)}},
+			}, nil
+		}
+	}
+
+	t.Run("returns primary response", func(t *testing.T) {
+		shadowDone := make(chan string, 1)
+		release := make(chan struct{})
+		primary := &MockTransport{SendRequestFunc: respond("primary")}
+		shadow := &MockTransport{SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			// A slow shadow must not delay the primary
+			<-release
+			return respond("shadow")(ctx, input)
+		}}
+
+		transport := NewTeeTransport(primary, shadow, func(output *SendRequestOutput, err error) {
+			if err != nil {
+				shadowDone <- err.Error()
+				return
+			}
+			shadowDone <- string(output.Responses[0].Result)
+		})
+		client := NewClient(transport)
+
+		invoke := &Invoke[map[string]string, string]{Name: "test.method", Request: map[string]string{}}
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if invoke.Response != "primary" {
+			t.Errorf("expected result: primary, got: %s", invoke.Response)
+		}
+
+		close(release)
+		select {
+		case got := <-shadowDone:
+			if got != `"shadow"` {
+				t.Errorf("expected shadow result: \"shadow\", got: %s", got)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("shadow callback was not called")
+		}
+	})
+
+	t.Run("shadow error is not returned", func(t *testing.T) {
+		shadowErr := make(chan error, 1)
+		primary := &MockTransport{SendRequestFunc: respond("primary")}
+		shadow := &MockTransport{SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			return nil, errors.New("shadow down")
+		}}
+
+		client := NewClient(NewTeeTransport(primary, shadow, func(output *SendRequestOutput, err error) {
+			shadowErr <- err
+		}))
+
+		invoke := &Invoke[map[string]string, string]{Name: "test.method", Request: map[string]string{}}
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+
+		select {
+		case err := <-shadowErr:
+			if err == nil {
+				t.Error("expected shadow error to be reported to the callback")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("shadow callback was not called")
+		}
+	})
+
+	t.Run("shadow outlives the call context", func(t *testing.T) {
+		shadowCtxErr := make(chan error, 1)
+		primary := &MockTransport{SendRequestFunc: respond("primary")}
+		shadow := &MockTransport{SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			time.Sleep(10 * time.Millisecond)
+			shadowCtxErr <- ctx.Err()
+			return nil, nil
+		}}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		client := NewClient(NewTeeTransport(primary, shadow, nil))
+		invoke := &Invoke[map[string]string, string]{Name: "test.method", Request: map[string]string{}}
+		if err := client.Invoke(ctx, invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		cancel()
+
+		if err := <-shadowCtxErr; err != nil {
+			t.Errorf("expected shadow context to stay active, got: %v", err)
+		}
+	})
+	t.Run("shadow timeout", func(t *testing.T) {
+		shadowErr := make(chan error, 1)
+		primary := &MockTransport{SendRequestFunc: respond("primary")}
+		// A hanging shadow only returns when its context ends
+		shadow := &MockTransport{SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}}
+
+		client := NewClient(NewTeeTransport(primary, shadow, func(output *SendRequestOutput, err error) {
+			shadowErr <- err
+		}, WithShadowTimeout(10*time.Millisecond)))
+		invoke := &Invoke[map[string]string, string]{Name: "test.method", Request: map[string]string{}}
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+
+		select {
+		case err := <-shadowErr:
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected error: %v, got: %v", context.DeadlineExceeded, err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("shadow was not bounded by the timeout")
+		}
+	})
+
+	t.Run("shadows beyond the limit are dropped", func(t *testing.T) {
+		release := make(chan struct{})
+		results := make(chan error, 2)
+		shadowCalls := 0
+		primary := &MockTransport{SendRequestFunc: respond("primary")}
+		shadow := &MockTransport{SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			shadowCalls++
+			<-release
+			return respond("shadow")(ctx, input)
+		}}
+
+		client := NewClient(NewTeeTransport(primary, shadow, func(output *SendRequestOutput, err error) {
+			results <- err
+		}, WithMaxShadows(1)))
+		for i := 0; i < 2; i++ {
+			invoke := &Invoke[map[string]string, string]{Name: "test.method", Request: map[string]string{}}
+			if err := client.Invoke(context.Background(), invoke); err != nil {
+				t.Fatalf("Invoke error: %v", err)
+			}
+		}
+
+		// The second call is dropped while the first shadow is still in flight
+		var droppedErr *ShadowDroppedError
+		if err := <-results; !errors.As(err, &droppedErr) {
+			t.Fatalf("expected error type: *ShadowDroppedError, got: %T", err)
+		}
+		if droppedErr.Method != "test.method" || droppedErr.Limit != 1 {
+			t.Errorf("expected method test.method and limit 1, got: %s and %d", droppedErr.Method, droppedErr.Limit)
+		}
+		close(release)
+		if err := <-results; err != nil {
+			t.Errorf("expected the first shadow to succeed, got: %v", err)
+		}
+		if shadowCalls != 1 {
+			t.Errorf("expected 1 shadow call, got: %d", shadowCalls)
+		}
+	})
+	t.Run("shadow is not affected by later changes to params", func(t *testing.T) {
+		shadowParams := make(chan string, 1)
+		primary := &MockTransport{SendRequestFunc: respond("primary")}
+		shadow := &MockTransport{SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			data, _ := json.Marshal(input.Requests[0].Params)
+			shadowParams <- string(data)
+			return respond("shadow")(ctx, input)
+		}}
+
+		client := NewClient(NewTeeTransport(primary, shadow, nil))
+		params := map[string]int{"n": 1}
+		invoke := &Invoke[map[string]int, string]{Name: "test.method", Request: params}
+		if err := client.Invoke(context.Background(), invoke); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		// The caller reuses its params map while the shadow may still be encoding it
+		params["n"] = 2
+
+		select {
+		case got := <-shadowParams:
+			if got != `{"n":1}` {
+				t.Errorf("expected shadow params: {\"n\":1}, got: %s", got)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("shadow was not called")
+		}
+	})
+}
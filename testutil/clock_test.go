@@ -0,0 +1,46 @@
+package testutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	if !clock.Now().Equal(start) {
+		t.Errorf("expected now: %v, got: %v", start, clock.Now())
+	}
+
+	ch := clock.After(time.Second)
+	if clock.Waiters() != 1 {
+		t.Errorf("expected 1 waiter, got: %d", clock.Waiters())
+	}
+
+	clock.Advance(500 * time.Millisecond)
+	select {
+	case <-ch:
+		t.Fatal("timer fired too early")
+	default:
+	}
+
+	clock.Advance(500 * time.Millisecond)
+	select {
+	case got := <-ch:
+		if want := start.Add(time.Second); !got.Equal(want) {
+			t.Errorf("expected fire time: %v, got: %v", want, got)
+		}
+	default:
+		t.Fatal("timer did not fire")
+	}
+	if clock.Waiters() != 0 {
+		t.Errorf("expected 0 waiters, got: %d", clock.Waiters())
+	}
+
+	select {
+	case <-clock.After(0):
+	default:
+		t.Error("expected zero duration timer to fire immediately")
+	}
+}
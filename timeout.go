@@ -0,0 +1,46 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"time"
+)
+
+// WithTimeout sets a default deadline applied to every call that has no method-specific
+// timeout. A deadline already on the incoming context is kept when it is shorter.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithMethodTimeout sets per-method deadlines that override the default timeout.
+// A deadline already on the incoming context is kept when it is shorter.
+func WithMethodTimeout(timeouts map[string]time.Duration) ClientOption {
+	return func(c *Client) {
+		c.methodTimeouts = timeouts
+	}
+}
+
+// timeoutFor returns the timeout configured for method, or zero if there is none
+func (c *Client) timeoutFor(method string) time.Duration {
+	if timeout, ok := c.methodTimeouts[method]; ok {
+		return timeout
+	}
+	return c.timeout
+}
+
+// withTimeout derives a context bounded by the longest timeout configured for the given
+// requests, so that no call in a batch is cut short
+func (c *Client) withTimeout(ctx context.Context, requests []*JSONRPCRequest) (context.Context, context.CancelFunc) {
+	var timeout time.Duration
+	for _, request := range requests {
+		if t := c.timeoutFor(request.Method); t > timeout {
+			timeout = t
+		}
+	}
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	// context.WithTimeout keeps the parent's deadline if it is earlier
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,83 @@
+package jsonrpc_client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestWithMethodTimeout(t *testing.T) {
+	// The transport records the deadline of the context it receives
+	var remaining time.Duration
+	var hasDeadline bool
+	transport := &MockTransport{
+		SendRequestFunc: func(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+			var deadline time.Time
+			deadline, hasDeadline = ctx.Deadline()
+			remaining = time.Until(deadline)
+			output := &SendRequestOutput{}
+			for _, request := range input.Requests {
+				output.Responses = append(output.Responses, &JSONRPCResponse{ID: request.ID, Result: json.RawMessage(`"ok"`)})
+			}
+			return output, nil
+		},
+	}
+	client := NewClient(transport,
+		WithTimeout(2*time.Second),
+		WithMethodTimeout(map[string]time.Duration{"report": time.Minute}),
+	)
+
+	invoke := func(ctx context.Context, method string) {
+		t.Helper()
+		if err := client.Invoke(ctx, &Invoke[Omit, string]{Name: method}); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+	}
+
+	t.Run("method with specific timeout", func(t *testing.T) {
+		invoke(context.Background(), "report")
+		if !hasDeadline || remaining <= 2*time.Second || remaining > time.Minute {
+			t.Errorf("expected deadline about 1m away, got: %v", remaining)
+		}
+	})
+
+	t.Run("method without specific timeout", func(t *testing.T) {
+		invoke(context.Background(), "add")
+		if !hasDeadline || remaining > 2*time.Second {
+			t.Errorf("expected deadline about 2s away, got: %v", remaining)
+		}
+	})
+
+	t.Run("shorter incoming deadline wins", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		invoke(ctx, "report")
+		if !hasDeadline || remaining > 100*time.Millisecond {
+			t.Errorf("expected deadline about 100ms away, got: %v", remaining)
+		}
+	})
+
+	t.Run("batch uses the longest timeout", func(t *testing.T) {
+		err := client.InvokeBatch(context.Background(), []MethodCaller{
+			&Invoke[Omit, string]{Name: "add"},
+			&Invoke[Omit, string]{Name: "report"},
+		})
+		if err != nil {
+			t.Fatalf("InvokeBatch error: %v", err)
+		}
+		if !hasDeadline || remaining <= 2*time.Second {
+			t.Errorf("expected deadline about 1m away, got: %v", remaining)
+		}
+	})
+
+	t.Run("no timeout configured", func(t *testing.T) {
+		client := NewClient(transport)
+		if err := client.Invoke(context.Background(), &Invoke[Omit, string]{Name: "add"}); err != nil {
+			t.Fatalf("Invoke error: %v", err)
+		}
+		if hasDeadline {
+			t.Errorf("expected no deadline, got: %v", remaining)
+		}
+	})
+}
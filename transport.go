@@ -3,8 +3,15 @@ package jsonrpc_client
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
 )
 
 // SendRequestInput represents input parameters for sending a request
@@ -29,8 +36,87 @@ type HTTPTransport struct {
 	client  *http.Client
 	baseURL string
 	headers map[string]string
+	signer  RequestSigner
+	mutator func(*http.Request) error
+
+	canonicalSigner CanonicalSigner
+
+	formEncoded       bool
+	envelope          []string
+	encoder           RequestEncoder
+	decoder           ResponseDecoder
+	decodeErrorBodies bool
+	idField           string
+
+	indented     bool
+	indentPrefix string
+	indent       string
+
+	maxRequestBytes int
+	captureOnError  ByteDumper
+	path            string
+	methodPath      func(method string) string
+	contextHeaders  []contextHeader
+	tolerantGzip    bool
+	gzipWarn        func(method, message string)
+	gzipRequests    func() bool
+	baseCtx         context.Context
+	tlsInfo         func(method string, state *tls.ConnectionState)
+
+	stats transportCounters
+}
+
+// RequestSigner computes a signature over the encoded request body and returns
+// the header name and value that carry it
+type RequestSigner func(body []byte) (headerName, headerValue string, err error)
+
+// CanonicalRequest holds the final request inputs passed to a CanonicalSigner
+type CanonicalRequest struct {
+	// Method is the HTTP method, always POST
+	Method string
+	// URL is the resolved endpoint, including any per-call override from WithEndpoint
+	URL *url.URL
+	// Header is a copy of the headers that will be sent: Content-Type, the headers from
+	// WithHTTPHeaders and the header of WithRequestSigner, if any
+	Header http.Header
+	// Body is the exact encoded request body
+	Body []byte
+}
+
+// String returns the canonical form of the request. It consists of the method, the
+// request URI (path and query) and one "name:value" line per header, followed by an
+// empty line and the body. Lines are separated by "\n", header names are lowercased and
+// sorted, and multiple values of the same header are joined by ",".
+func (r *CanonicalRequest) String() string {
+	names := make([]string, 0, len(r.Header))
+	values := make(map[string][]string, len(r.Header))
+	for name, v := range r.Header {
+		lower := strings.ToLower(name)
+		names = append(names, lower)
+		values[lower] = append(values[lower], v...)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(r.Method)
+	b.WriteByte('\n')
+	b.WriteString(r.URL.RequestURI())
+	b.WriteByte('\n')
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(strings.Join(values[name], ","))
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+	b.Write(r.Body)
+	return b.String()
 }
 
+// CanonicalSigner computes a signature over the final request and returns the header name
+// and value that carry it. CanonicalRequest.String gives a ready-made canonical string.
+type CanonicalSigner func(req *CanonicalRequest) (headerName, headerValue string, err error)
+
 type HTTPTransportOption func(*HTTPTransport)
 
 // WithHTTPClient sets the HTTP client for the transport
@@ -47,6 +133,142 @@ func WithHTTPHeaders(headers map[string]string) HTTPTransportOption {
 	}
 }
 
+// WithRequestSigner sets a signer that is called with the exact encoded request body
+// (the whole array for batches) before the request is sent
+func WithRequestSigner(signer RequestSigner) HTTPTransportOption {
+	return func(t *HTTPTransport) {
+		t.signer = signer
+	}
+}
+
+// WithCanonicalSigner sets a signer that is called with the HTTP method, URL, headers and
+// body of the request after all other headers are set and before WithRequestMutator runs,
+// for auth schemes that sign more than the body
+func WithCanonicalSigner(signer CanonicalSigner) HTTPTransportOption {
+	return func(t *HTTPTransport) {
+		t.canonicalSigner = signer
+	}
+}
+
+// WithRequestMutator sets a function that can modify the fully built *http.Request after
+// headers are set and right before it is sent, e.g. to apply AWS SigV4 signing. The body can
+// be read without consuming it via req.GetBody. An error aborts the call as InvokeError.
+func WithRequestMutator(mutator func(*http.Request) error) HTTPTransportOption {
+	return func(t *HTTPTransport) {
+		t.mutator = mutator
+	}
+}
+
+// WithFormEncodedParams sends single requests as an application/x-www-form-urlencoded
+// body instead of JSON. The jsonrpc, method and id members become form fields and each
+// member of the params object becomes a field of its own. Responses are still decoded
+// as JSON. Batch requests cannot be form-encoded and are rejected.
+func WithFormEncodedParams() HTTPTransportOption {
+	return func(t *HTTPTransport) {
+		t.formEncoded = true
+	}
+}
+
+// WithResponseEnvelope sets a dotted path (e.g. "data.jsonrpc_response") locating the
+// JSON-RPC response, or batch response array, inside a wrapping JSON object
+func WithResponseEnvelope(path string) HTTPTransportOption {
+	return func(t *HTTPTransport) {
+		if path == "" {
+			t.envelope = nil
+			return
+		}
+		t.envelope = strings.Split(path, ".")
+	}
+}
+
+// WithDecodeErrorBodies makes the transport look for a JSON-RPC error object in the body of
+// a non-200 response, as sent by some gateways. If one is found it is returned as RPCError;
+// otherwise the usual StatusCodeError is returned.
+func WithDecodeErrorBodies() HTTPTransportOption {
+	return func(t *HTTPTransport) {
+		t.decodeErrorBodies = true
+	}
+}
+
+// WithResponseIDField sets the name of the response member that carries the request ID, for
+// servers that echo it in a non-standard member such as "reqId". The default is "id".
+func WithResponseIDField(name string) HTTPTransportOption {
+	return func(t *HTTPTransport) {
+		if name == "id" {
+			name = ""
+		}
+		t.idField = name
+	}
+}
+
+// WithIndentedRequests pretty-prints JSON request bodies as json.MarshalIndent would, which
+// is handy when reading traffic while debugging. The default is compact output. Indentation
+// adds whitespace and newlines that some strict or signature-checking servers may reject,
+// so it should not be used in production.
+func WithIndentedRequests(prefix, indent string) HTTPTransportOption {
+	return func(t *HTTPTransport) {
+		t.indented = true
+		t.indentPrefix = prefix
+		t.indent = indent
+	}
+}
+
+// WithMaxRequestBytes sets the largest request body, in bytes, the transport will send.
+// A larger body fails with RequestTooLargeError before it is sent, instead of being
+// rejected by the server with an opaque 413. Zero means no limit.
+func WithMaxRequestBytes(n int) HTTPTransportOption {
+	return func(t *HTTPTransport) {
+		t.maxRequestBytes = n
+	}
+}
+
+// WithPath sets a path that is joined onto the base URL of every request, so the base URL
+// can be just the host, e.g. "https://example.com" with "/rpc". Leading and trailing slashes
+// on either side are handled, and a query string on the base URL is kept. The path is also
+// joined onto an endpoint set per call with WithEndpoint.
+func WithPath(path string) HTTPTransportOption {
+	return func(t *HTTPTransport) {
+		t.path = path
+	}
+}
+
+// WithMethodPath routes each request to a URL path derived from its method, e.g.
+// POST /rpc/add for gateways that expose one path per method. The path is joined onto the
+// base URL after WithPath, and an empty path leaves the URL unchanged. A batch can only be
+// sent when all of its methods map to the same path; otherwise it fails with
+// InvalidRequestError before anything is sent.
+func WithMethodPath(methodPath func(method string) string) HTTPTransportOption {
+	return func(t *HTTPTransport) {
+		t.methodPath = methodPath
+	}
+}
+
+// contextHeader is a header whose value is read from the request context
+type contextHeader struct {
+	name  string
+	value func(ctx context.Context) (string, bool)
+}
+
+// WithHeaderFromContext sets the header name on each request to the value fn reads from the
+// call's context, e.g. a request or tenant ID propagated across services. The header is left
+// out when fn reports no value. It can be given several times, once per header, and takes
+// precedence over a static header of the same name from WithHTTPHeaders.
+func WithHeaderFromContext(name string, fn func(ctx context.Context) (string, bool)) HTTPTransportOption {
+	return func(t *HTTPTransport) {
+		t.contextHeaders = append(t.contextHeaders, contextHeader{name: name, value: fn})
+	}
+}
+
+// WithTLSInfo calls fn with the negotiated TLS connection state of each HTTP call once the
+// response headers have arrived, e.g. to audit the TLS version and cipher suite in use. The
+// state is nil when the endpoint is not served over TLS. For a batch, method is the method
+// of the first request.
+func WithTLSInfo(fn func(method string, state *tls.ConnectionState)) HTTPTransportOption {
+	return func(t *HTTPTransport) {
+		t.tlsInfo = fn
+	}
+}
+
 // NewHTTPTransport creates a transport for sending JSON-RPC requests via HTTP
 func NewHTTPTransport(baseURL string, opts ...HTTPTransportOption) *HTTPTransport {
 	t := &HTTPTransport{
@@ -59,60 +281,493 @@ func NewHTTPTransport(baseURL string, opts ...HTTPTransportOption) *HTTPTranspor
 	return t
 }
 
+// NewTransportForURL creates the transport for the scheme of rawURL, so an endpoint can be
+// configured as a single URL string. http and https give an HTTPTransport configured with
+// opts. Stream schemes (ws, wss, unix, tcp) are recognized but not implemented by this
+// package, and other schemes are rejected; both return an error.
+func NewTransportForURL(rawURL string, opts ...HTTPTransportOption) (Transport, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transport URL %q: %w", rawURL, err)
+	}
+	switch scheme := strings.ToLower(u.Scheme); scheme {
+	case "http", "https":
+		if u.Host == "" {
+			return nil, fmt.Errorf("transport URL %q has no host", rawURL)
+		}
+		return NewHTTPTransport(rawURL, opts...), nil
+	case "ws", "wss", "unix", "tcp":
+		return nil, fmt.Errorf("transport URL %q: scheme %q is not supported by this package", rawURL, scheme)
+	default:
+		return nil, fmt.Errorf("transport URL %q: unknown scheme %q", rawURL, u.Scheme)
+	}
+}
+
+// maxPooledBufferSize caps the size of buffers returned to the pool so that an occasional
+// huge request does not keep a large allocation alive
+const maxPooledBufferSize = 1 << 20
+
+// bufferPool holds request body buffers shared by all HTTP transports
+var bufferPool = sync.Pool{
+	New: func() any {
+		return new(bytes.Buffer)
+	},
+}
+
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	bufferPool.Put(buf)
+}
+
+// encodeJSON writes the JSON encoding of v to buf without the trailing newline added by
+// json.Encoder. Encoding straight into the pooled buffer allocates less than json.Marshal,
+// and the output is byte-for-byte identical to json.Marshal.
+func encodeJSON(buf *bytes.Buffer, v any) error {
+	if err := json.NewEncoder(buf).Encode(v); err != nil {
+		return err
+	}
+	buf.Truncate(buf.Len() - 1)
+	return nil
+}
+
+// encodeRequestJSON writes the JSON encoding of v to buf, indented if WithIndentedRequests is set
+func (t *HTTPTransport) encodeRequestJSON(buf *bytes.Buffer, v any) error {
+	if !t.indented {
+		return encodeJSON(buf, v)
+	}
+	enc := json.NewEncoder(buf)
+	enc.SetIndent(t.indentPrefix, t.indent)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	buf.Truncate(buf.Len() - 1)
+	return nil
+}
+
 // SendRequest sends a JSON-RPC request via HTTP
 func (t *HTTPTransport) SendRequest(ctx context.Context, input *SendRequestInput) (*SendRequestOutput, error) {
+	t.stats.requests.Add(1)
+	output, err := t.sendRequest(ctx, input)
+	if err != nil {
+		t.stats.errors.Add(1)
+	}
+	return output, err
+}
+
+func (t *HTTPTransport) sendRequest(ctx context.Context, input *SendRequestInput) (_ *SendRequestOutput, err error) {
 	if len(input.Requests) == 0 {
 		return nil, &InvalidRequestError{Message: "no request provided"}
 	}
+	if t.baseCtx != nil {
+		ctx = valuesContext{Context: ctx, base: t.baseCtx}
+	}
 
 	method := input.Requests[0].Method
-	body := bytes.NewBuffer(nil)
+	// The pooled buffers are only used to encode the request. The HTTP client may read the
+	// request body after SendRequest returns, e.g. for redirects or HTTP/2 retries through
+	// GetBody, so it is sent from a copy that is never returned to the pool.
+	body := getBuffer()
+	defer putBuffer(body)
+
+	// encoded keeps the request bytes for WithCaptureBytesOnError, before any compression
+	var encoded []byte
+	var captured *limitedBuffer
+	if t.captureOnError != nil {
+		captured = &limitedBuffer{limit: maxCapturedResponseBytes}
+		// Runs after the response body is drained and before the request buffer is released
+		defer func() {
+			if err != nil {
+				t.captureOnError(encoded, captured.Bytes(), err)
+			}
+		}()
+	}
+	contentType := "application/json"
 
-	if input.Batch {
-		if err := json.NewEncoder(body).Encode(input.Requests); err != nil {
+	if t.encoder != nil {
+		if err := t.encoder.EncodeRequest(body, input); err != nil {
 			return nil, &MarshalError{Method: method, Err: err}
 		}
-	} else {
-		if err := json.NewEncoder(body).Encode(input.Requests[0]); err != nil {
+	} else if t.formEncoded {
+		if input.Batch {
+			return nil, &InvalidRequestError{Message: "batch requests cannot be form-encoded"}
+		}
+		values, err := encodeFormRequest(input.Requests[0])
+		if err != nil {
+			return nil, &MarshalError{Method: method, Err: err}
+		}
+		body.WriteString(values.Encode())
+		contentType = "application/x-www-form-urlencoded"
+	} else if err := encodeRequests(body, input.Requests, input.Batch, t.encodeRequestJSON); err != nil {
+		return nil, &MarshalError{Method: method, Err: err}
+	}
+
+	encoded = body.Bytes()
+	if t.maxRequestBytes > 0 && body.Len() > t.maxRequestBytes {
+		return nil, &RequestTooLargeError{Method: method, Size: body.Len(), Limit: t.maxRequestBytes, Batch: input.Batch}
+	}
+
+	compressed := t.gzipRequests != nil && t.gzipRequests()
+	if compressed {
+		zipped := getBuffer()
+		defer putBuffer(zipped)
+		if err := gzipTo(zipped, body.Bytes()); err != nil {
 			return nil, &MarshalError{Method: method, Err: err}
 		}
+		body = zipped
+	}
+
+	var signatureName, signatureValue string
+	if t.signer != nil {
+		var err error
+		signatureName, signatureValue, err = t.signer(body.Bytes())
+		if err != nil {
+			return nil, &MarshalError{Method: method, Err: err}
+		}
+	}
+
+	endpoint := t.baseURL
+	if override, ok := EndpointFromContext(ctx); ok {
+		if err := validateEndpoint(override); err != nil {
+			return nil, &InvalidRequestError{Message: fmt.Sprintf("invalid endpoint for [%s]: %v", method, err)}
+		}
+		endpoint = override
+	}
+	path := t.path
+	if t.methodPath != nil {
+		routed, err := t.routeMethods(input.Requests)
+		if err != nil {
+			return nil, err
+		}
+		if routed != "" {
+			path += "/" + routed
+		}
+	}
+	if path != "" {
+		joined, err := joinEndpointPath(endpoint, path)
+		if err != nil {
+			return nil, &InvalidRequestError{Message: fmt.Sprintf("invalid endpoint for [%s]: %v", method, err)}
+		}
+		endpoint = joined
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", t.baseURL, body)
+	// GetBody, set for a bytes.Reader, reads the same copy, which nothing else writes to
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(bytes.Clone(body.Bytes())))
 	if err != nil {
 		return nil, &MarshalError{Method: method, Err: err}
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", contentType)
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
 	for key, value := range t.headers {
 		req.Header.Set(key, value)
 	}
+	for _, header := range t.contextHeaders {
+		if value, ok := header.value(ctx); ok {
+			req.Header.Set(header.name, value)
+		}
+	}
+	if t.tolerantGzip && req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+	if signatureName != "" {
+		req.Header.Set(signatureName, signatureValue)
+	}
+	if t.canonicalSigner != nil {
+		u := *req.URL
+		name, value, err := t.canonicalSigner(&CanonicalRequest{
+			Method: req.Method,
+			URL:    &u,
+			Header: req.Header.Clone(),
+			Body:   body.Bytes(),
+		})
+		if err != nil {
+			return nil, &MarshalError{Method: method, Err: err}
+		}
+		req.Header.Set(name, value)
+	}
+	if t.mutator != nil {
+		if err := t.mutator(req); err != nil {
+			return nil, &InvokeError{Method: method, Err: err, permanent: true}
+		}
+	}
 
+	t.stats.bytesSent.Add(uint64(body.Len()))
 	resp, err := t.client.Do(req)
 	if err != nil {
 		return nil, &InvokeError{Method: method, Err: err}
 	}
-	defer resp.Body.Close()
+	if t.tlsInfo != nil {
+		t.tlsInfo(method, resp.TLS)
+	}
+	resp.Body = &countingReadCloser{ReadCloser: resp.Body, n: &t.stats.bytesReceived}
+	if captured != nil {
+		resp.Body = newTeeReadCloser(resp.Body, captured)
+	}
+	defer drainAndClose(resp.Body)
+	if t.tolerantGzip && strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		resp.Body = t.gunzip(method, resp.Body)
+	}
+
+	// Notifications expect no response. Servers may still reply with an empty body or an
+	// ack such as {"jsonrpc":"2.0","result":null}, so any 2xx body is accepted. Replies that
+	// can be decoded are returned so the client can report them.
+	if allNotifications(input.Requests) {
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return nil, &StatusCodeError{Method: method, StatusCode: resp.StatusCode}
+		}
+		return &SendRequestOutput{Responses: decodeNotificationReplies(resp.Body)}, nil
+	}
 
 	if resp.StatusCode != http.StatusOK {
+		if t.decodeErrorBodies {
+			if err := decodeErrorBody(resp.Body, method); err != nil {
+				return nil, err
+			}
+		}
 		return nil, &StatusCodeError{Method: method, StatusCode: resp.StatusCode}
 	}
 
+	var respBody io.Reader = resp.Body
+	if len(t.envelope) > 0 {
+		raw, err := extractEnvelope(resp.Body, t.envelope)
+		if err != nil {
+			return nil, &UnmarshalError{Method: method, Err: err}
+		}
+		respBody = bytes.NewReader(raw)
+	}
+
 	output := &SendRequestOutput{}
 
-	if input.Batch {
-		// Decode batch response
-		if err := json.NewDecoder(resp.Body).Decode(&output.Responses); err != nil {
+	if t.decoder != nil {
+		responses, err := t.decoder.DecodeResponse(respBody, input)
+		if err != nil {
 			return nil, &UnmarshalError{Method: method, Err: err}
 		}
+		output.Responses = responses
 	} else {
-		// Process single request
-		var response *JSONRPCResponse
-		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		responses, err := decodeResponses(respBody, input.Batch, t.idField)
+		if err != nil {
 			return nil, &UnmarshalError{Method: method, Err: err}
 		}
-		output.Responses = []*JSONRPCResponse{response}
+		if input.Batch {
+			if err := batchLevelError(input.Requests, responses, method); err != nil {
+				return nil, err
+			}
+		}
+		output.Responses = responses
 	}
 
 	return output, nil
 }
+
+// routeMethods returns the WithMethodPath path shared by all requests
+func (t *HTTPTransport) routeMethods(requests []*JSONRPCRequest) (string, error) {
+	path := t.methodPath(requests[0].Method)
+	for _, request := range requests[1:] {
+		if other := t.methodPath(request.Method); other != path {
+			return "", &InvalidRequestError{Message: fmt.Sprintf(
+				"batch methods %q and %q map to different paths %q and %q",
+				requests[0].Method, request.Method, path, other)}
+		}
+	}
+	return path, nil
+}
+
+// joinEndpointPath appends path to the path of endpoint with exactly one slash between them
+func joinEndpointPath(endpoint, path string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	return u.JoinPath(path).String(), nil
+}
+
+// decodeErrorBody returns the JSON-RPC error carried by the body of a non-200 response as
+// an RPCError, or nil if the body is not a JSON-RPC error response
+func decodeErrorBody(body io.Reader, method string) error {
+	var response JSONRPCResponse
+	if err := json.NewDecoder(io.LimitReader(body, maxDrainBytes)).Decode(&response); err != nil {
+		return nil
+	}
+	return response.AsError(method)
+}
+
+// maxDrainBytes bounds how much of an unread response body is discarded before closing it.
+// Larger leftovers are not worth reading just to reuse the connection.
+const maxDrainBytes = 256 << 10
+
+// drainAndClose discards the unread remainder of body, up to maxDrainBytes, and closes it.
+// http.Client only reuses a keep-alive connection once the previous body has been read to
+// EOF, so every path, including StatusCodeError and decode errors, goes through here.
+func drainAndClose(body io.ReadCloser) {
+	io.CopyN(io.Discard, body, maxDrainBytes)
+	body.Close()
+}
+
+// allNotifications reports whether none of the requests expects a response
+func allNotifications(requests []*JSONRPCRequest) bool {
+	for _, request := range requests {
+		if !request.ID.IsNotification() {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeNotificationReplies decodes what a server sent back for notifications, which may be
+// nothing, a single response or an array of them. Anything that does not decode is ignored.
+func decodeNotificationReplies(r io.Reader) []*JSONRPCResponse {
+	body, err := io.ReadAll(io.LimitReader(r, maxDrainBytes))
+	if err != nil {
+		return nil
+	}
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil
+	}
+	if body[0] == '[' {
+		var responses []*JSONRPCResponse
+		if json.Unmarshal(body, &responses) != nil {
+			return nil
+		}
+		return responses
+	}
+	var response *JSONRPCResponse
+	if json.Unmarshal(body, &response) != nil || response == nil {
+		return nil
+	}
+	return []*JSONRPCResponse{response}
+}
+
+// decodeResponse decodes a single response. A non-empty idField names the member that
+// carries the request ID in place of "id".
+func decodeResponse(r io.Reader, idField string) (*JSONRPCResponse, error) {
+	var response *JSONRPCResponse
+	if idField == "" {
+		if err := json.NewDecoder(r).Decode(&response); err != nil {
+			return nil, err
+		}
+		return response, nil
+	}
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(renameIDField(raw, idField), &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// decodeBatchResponses decodes a batch response array element by element, so that a single
+// malformed element does not discard the others. An element whose ID can still be read is
+// kept with its decode error recorded; other malformed elements are dropped. A single
+// object instead of an array is decoded as the only response.
+// A non-empty idField names the member that carries the request ID in place of "id".
+func decodeBatchResponses(r io.Reader, idField string) ([]*JSONRPCResponse, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+	// Some servers answer a batch with a single object, typically an error for the batch
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '{' {
+		response, err := decodeResponse(bytes.NewReader(trimmed), idField)
+		if err != nil {
+			return nil, err
+		}
+		return []*JSONRPCResponse{response}, nil
+	}
+
+	var elements []json.RawMessage
+	if err := json.Unmarshal(raw, &elements); err != nil {
+		return nil, err
+	}
+
+	responses := make([]*JSONRPCResponse, 0, len(elements))
+	for _, element := range elements {
+		if idField != "" {
+			element = renameIDField(element, idField)
+		}
+		var response JSONRPCResponse
+		if err := json.Unmarshal(element, &response); err != nil {
+			var idOnly struct {
+				ID *IDValue `json:"id"`
+			}
+			if json.Unmarshal(element, &idOnly) != nil || idOnly.ID == nil {
+				continue
+			}
+			response = JSONRPCResponse{ID: idOnly.ID, decodeErr: err}
+		}
+		responses = append(responses, &response)
+	}
+	return responses, nil
+}
+
+// batchLevelError returns the error of a batch answered by a lone error response without
+// an ID, e.g. a single {"id":null,"error":...} object rejecting the whole batch. It is nil
+// when a request of the batch has an explicitly null ID, which the response may answer.
+func batchLevelError(requests []*JSONRPCRequest, responses []*JSONRPCResponse, method string) error {
+	if len(responses) != 1 || responses[0] == nil || responses[0].ID != nil || responses[0].Error == nil {
+		return nil
+	}
+	for _, request := range requests {
+		if request.ID.IsExplicitlyNull() {
+			return nil
+		}
+	}
+	return responses[0].AsError(method)
+}
+
+// renameIDField moves the member named field of the response object raw to "id". Values
+// that are not objects or lack the member are returned unchanged.
+func renameIDField(raw json.RawMessage, field string) json.RawMessage {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err != nil || obj == nil {
+		return raw
+	}
+	id, ok := obj[field]
+	if !ok {
+		return raw
+	}
+	obj["id"] = id
+	delete(obj, field)
+	renamed, err := json.Marshal(obj)
+	if err != nil {
+		return raw
+	}
+	return renamed
+}
+
+// extractEnvelope walks path through nested JSON objects read from r and returns the value found
+func extractEnvelope(r io.Reader, path []string) (json.RawMessage, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+	for i, key := range path {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &obj); err != nil || obj == nil {
+			if i == 0 {
+				return nil, fmt.Errorf("response body is not an object")
+			}
+			return nil, fmt.Errorf("response envelope %q is not an object", strings.Join(path[:i], "."))
+		}
+		value, ok := obj[key]
+		if !ok {
+			return nil, fmt.Errorf("response envelope path %q not found", strings.Join(path[:i+1], "."))
+		}
+		raw = value
+	}
+	return raw, nil
+}
@@ -1,11 +1,21 @@
 package jsonrpc_client
 
 import (
+	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -924,3 +934,1299 @@ func TestHTTPTransportOptions(t *testing.T) {
 		}
 	})
 }
+
+func TestHTTPTransportRequestSigner(t *testing.T) {
+	secret := []byte("test-secret")
+	sign := func(body []byte) string {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(body)
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+	signer := func(body []byte) (string, string, error) {
+		return "X-Signature", sign(body), nil
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("failed to read body: %v", err)
+		}
+		if got, want := r.Header.Get("X-Signature"), sign(body); got != want {
+			t.Errorf("expected X-Signature: %s, got: %s", want, got)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if len(body) > 0 && body[0] == '[' {
+			w.Write([]byte(`[{"jsonrpc":"2.0","id":1,"result":"ok"},{"jsonrpc":"2.0","id":2,"result":"ok"}]`))
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"ok"}`))
+	}))
+	defer server.Close()
+
+	transport := NewHTTPTransport(server.URL, WithRequestSigner(signer))
+
+	t.Run("single request", func(t *testing.T) {
+		input := &SendRequestInput{
+			Requests: []*JSONRPCRequest{
+				{Version: "2.0", ID: NewID(1), Method: "test.method"},
+			},
+		}
+		if _, err := transport.SendRequest(context.Background(), input); err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+	})
+
+	t.Run("batch request", func(t *testing.T) {
+		input := &SendRequestInput{
+			Requests: []*JSONRPCRequest{
+				{Version: "2.0", ID: NewID(1), Method: "test.method1"},
+				{Version: "2.0", ID: NewID(2), Method: "test.method2"},
+			},
+			Batch: true,
+		}
+		output, err := transport.SendRequest(context.Background(), input)
+		if err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+		if len(output.Responses) != 2 {
+			t.Errorf("expected 2 responses, got: %d", len(output.Responses))
+		}
+	})
+
+	t.Run("signer error", func(t *testing.T) {
+		signErr := errors.New("sign error")
+		transport := NewHTTPTransport(server.URL, WithRequestSigner(func(body []byte) (string, string, error) {
+			return "", "", signErr
+		}))
+		input := &SendRequestInput{
+			Requests: []*JSONRPCRequest{
+				{Version: "2.0", ID: NewID(1), Method: "test.method"},
+			},
+		}
+		_, err := transport.SendRequest(context.Background(), input)
+
+		var marshalErr *MarshalError
+		if !errors.As(err, &marshalErr) {
+			t.Fatalf("expected error type: *MarshalError, got: %T", err)
+		}
+		if marshalErr.Method != "test.method" {
+			t.Errorf("expected method: test.method, got: %s", marshalErr.Method)
+		}
+		if !errors.Is(err, signErr) {
+			t.Errorf("expected wrapped signer error, got: %v", err)
+		}
+	})
+}
+
+func TestHTTPTransportResponseEnvelope(t *testing.T) {
+	newServer := func(body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(body))
+		}))
+	}
+	single := &SendRequestInput{
+		Requests: []*JSONRPCRequest{{Version: "2.0", ID: NewID(1), Method: "test.method"}},
+	}
+
+	t.Run("single response", func(t *testing.T) {
+		server := newServer(`{"jsonrpc_response":{"jsonrpc":"2.0","id":1,"result":"ok"}}`)
+		defer server.Close()
+
+		transport := NewHTTPTransport(server.URL, WithResponseEnvelope("jsonrpc_response"))
+		output, err := transport.SendRequest(context.Background(), single)
+		if err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+		if string(output.Responses[0].Result) != `"ok"` {
+			t.Errorf("expected result: \"ok\", got: %s", output.Responses[0].Result)
+		}
+	})
+
+	t.Run("batch response with dotted path", func(t *testing.T) {
+		server := newServer(`{"data":{"rpc":[{"jsonrpc":"2.0","id":1,"result":1},{"jsonrpc":"2.0","id":2,"result":2}]}}`)
+		defer server.Close()
+
+		transport := NewHTTPTransport(server.URL, WithResponseEnvelope("data.rpc"))
+		output, err := transport.SendRequest(context.Background(), &SendRequestInput{
+			Requests: []*JSONRPCRequest{
+				{Version: "2.0", ID: NewID(1), Method: "test.method1"},
+				{Version: "2.0", ID: NewID(2), Method: "test.method2"},
+			},
+			Batch: true,
+		})
+		if err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+		if len(output.Responses) != 2 {
+			t.Fatalf("expected 2 responses, got: %d", len(output.Responses))
+		}
+	})
+
+	t.Run("missing path", func(t *testing.T) {
+		server := newServer(`{"data":{}}`)
+		defer server.Close()
+
+		transport := NewHTTPTransport(server.URL, WithResponseEnvelope("data.rpc"))
+		_, err := transport.SendRequest(context.Background(), single)
+
+		var unmarshalErr *UnmarshalError
+		if !errors.As(err, &unmarshalErr) {
+			t.Fatalf("expected error type: *UnmarshalError, got: %T", err)
+		}
+		if !strings.Contains(err.Error(), `"data.rpc" not found`) {
+			t.Errorf("expected missing path in error, got: %v", err)
+		}
+	})
+
+	t.Run("non-object envelope", func(t *testing.T) {
+		server := newServer(`{"data":[1,2]}`)
+		defer server.Close()
+
+		transport := NewHTTPTransport(server.URL, WithResponseEnvelope("data.rpc"))
+		_, err := transport.SendRequest(context.Background(), single)
+
+		var unmarshalErr *UnmarshalError
+		if !errors.As(err, &unmarshalErr) {
+			t.Fatalf("expected error type: *UnmarshalError, got: %T", err)
+		}
+	})
+}
+
+func BenchmarkHTTPTransportSendRequest(b *testing.B) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"ok"}`))
+	}))
+	defer server.Close()
+
+	transport := NewHTTPTransport(server.URL)
+	input := &SendRequestInput{
+		Requests: []*JSONRPCRequest{
+			{Version: "2.0", ID: NewID(1), Method: "test.method", Params: map[string]string{"key": strings.Repeat("x", 4096)}},
+		},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := transport.SendRequest(context.Background(), input); err != nil {
+			b.Fatalf("SendRequest error: %v", err)
+		}
+	}
+}
+
+func BenchmarkEncodeSingleRequest(b *testing.B) {
+	request := &JSONRPCRequest{
+		Version: "2.0",
+		ID:      NewID(1),
+		Method:  "test.method",
+		Params:  map[string]any{"a": 1, "b": "two", "c": []int{3, 4, 5}},
+	}
+
+	b.Run("encodeJSON", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buf := getBuffer()
+			if err := encodeJSON(buf, request); err != nil {
+				b.Fatal(err)
+			}
+			putBuffer(buf)
+		}
+	})
+
+	b.Run("Marshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buf := getBuffer()
+			data, err := json.Marshal(request)
+			if err != nil {
+				b.Fatal(err)
+			}
+			buf.Write(data)
+			putBuffer(buf)
+		}
+	})
+}
+
+func TestEncodeJSON(t *testing.T) {
+	request := &JSONRPCRequest{
+		Version: "2.0",
+		ID:      NewID(1),
+		Method:  "test.method",
+		Params:  map[string]string{"html": "<b>&</b>"},
+	}
+
+	expected, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := encodeJSON(buf, request); err != nil {
+		t.Fatalf("encodeJSON error: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Errorf("expected bytes: %s, got: %s", expected, buf.Bytes())
+	}
+}
+
+func TestHTTPTransportRequestBodyBytes(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("failed to read body: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if bytes.HasPrefix(body, []byte("[")) {
+			w.Write([]byte(`[{"jsonrpc":"2.0","id":1,"result":"ok"}]`))
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"ok"}`))
+	}))
+	defer server.Close()
+
+	transport := NewHTTPTransport(server.URL)
+	request := &JSONRPCRequest{Version: "2.0", ID: NewID(1), Method: "test.method", Params: []int{1, 2}}
+
+	t.Run("single request", func(t *testing.T) {
+		if _, err := transport.SendRequest(context.Background(), &SendRequestInput{Requests: []*JSONRPCRequest{request}}); err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+		expected := `{"jsonrpc":"2.0","id":1,"method":"test.method","params":[1,2]}`
+		if string(body) != expected {
+			t.Errorf("expected body: %q, got: %q", expected, body)
+		}
+	})
+
+	t.Run("batch request", func(t *testing.T) {
+		if _, err := transport.SendRequest(context.Background(), &SendRequestInput{Requests: []*JSONRPCRequest{request}, Batch: true}); err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+		expected := `[{"jsonrpc":"2.0","id":1,"method":"test.method","params":[1,2]}]`
+		if string(body) != expected {
+			t.Errorf("expected body: %q, got: %q", expected, body)
+		}
+	})
+}
+
+func TestDecodeBatchResponses(t *testing.T) {
+	body := `[
+		{"jsonrpc":"2.0","id":1,"result":"ok"},
+		{"jsonrpc":"2.0","id":2,"error":"not an error object"},
+		"garbage",
+		{"jsonrpc":"2.0","id":3,"result":"ok"}
+	]`
+
+	responses, err := decodeBatchResponses(strings.NewReader(body), "")
+	if err != nil {
+		t.Fatalf("decodeBatchResponses error: %v", err)
+	}
+	if len(responses) != 3 {
+		t.Fatalf("expected 3 responses, got: %d", len(responses))
+	}
+	if responses[0].decodeErr != nil || responses[2].decodeErr != nil {
+		t.Errorf("expected valid elements to decode cleanly")
+	}
+	if !responses[1].ID.Equal(NewID(2)) || responses[1].decodeErr == nil {
+		t.Errorf("expected element with id 2 to carry a decode error, got: %+v", responses[1])
+	}
+
+	if _, err := decodeBatchResponses(strings.NewReader(`[{"id":1}`), ""); err == nil {
+		t.Error("expected error for truncated array, got nil")
+	}
+}
+
+func TestHTTPTransportRequestMutator(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "signed:test.method" {
+			t.Errorf("expected Authorization: signed:test.method, got: %s", got)
+		}
+		if got := r.URL.Query().Get("region"); got != "us-east-1" {
+			t.Errorf("expected region query: us-east-1, got: %s", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"ok"}`))
+	}))
+	defer server.Close()
+
+	input := &SendRequestInput{
+		Requests: []*JSONRPCRequest{{Version: "2.0", ID: NewID(1), Method: "test.method"}},
+	}
+
+	t.Run("mutates request", func(t *testing.T) {
+		transport := NewHTTPTransport(server.URL,
+			WithHTTPHeaders(map[string]string{"X-API-Key": "key"}),
+			WithRequestMutator(func(req *http.Request) error {
+				if req.Header.Get("X-API-Key") != "key" {
+					t.Errorf("expected headers to be set before the mutator runs")
+				}
+				body, err := req.GetBody()
+				if err != nil {
+					return err
+				}
+				var request JSONRPCRequest
+				if err := json.NewDecoder(body).Decode(&request); err != nil {
+					return err
+				}
+				req.Header.Set("Authorization", "signed:"+request.Method)
+				req.URL.RawQuery = "region=us-east-1"
+				return nil
+			}),
+		)
+		if _, err := transport.SendRequest(context.Background(), input); err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+	})
+
+	t.Run("mutator error", func(t *testing.T) {
+		mutatorErr := errors.New("no credentials")
+		transport := NewHTTPTransport(server.URL, WithRequestMutator(func(req *http.Request) error {
+			return mutatorErr
+		}))
+		_, err := transport.SendRequest(context.Background(), input)
+
+		var invokeErr *InvokeError
+		if !errors.As(err, &invokeErr) {
+			t.Fatalf("expected error type: *InvokeError, got: %T", err)
+		}
+		if !errors.Is(err, mutatorErr) {
+			t.Errorf("expected wrapped mutator error, got: %v", err)
+		}
+	})
+}
+
+func TestHTTPTransportNotificationAck(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{name: "empty body", status: http.StatusOK, body: ""},
+		{name: "no content", status: http.StatusNoContent, body: ""},
+		{name: "accepted", status: http.StatusAccepted, body: ""},
+		{name: "null result ack", status: http.StatusOK, body: `{"jsonrpc":"2.0","result":null}`},
+		{name: "null result and id ack", status: http.StatusOK, body: `{"jsonrpc":"2.0","id":null,"result":null}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient(NewHTTPTransport(server.URL))
+			notification := AsNotification(&Invoke[[]string, Omit]{Name: "log", Request: []string{"hello"}})
+			if err := client.Invoke(context.Background(), notification); err != nil {
+				t.Errorf("expected no error, got: %v", err)
+			}
+		})
+	}
+
+	t.Run("non-2xx status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		client := NewClient(NewHTTPTransport(server.URL))
+		err := client.Invoke(context.Background(), AsNotification(&Invoke[[]string, Omit]{Name: "log", Request: []string{}}))
+
+		var statusErr *StatusCodeError
+		if !errors.As(err, &statusErr) {
+			t.Fatalf("expected error type: *StatusCodeError, got: %T", err)
+		}
+	})
+}
+
+func TestCanonicalRequestString(t *testing.T) {
+	u, _ := url.Parse("https://example.com/rpc/v1?region=eu")
+	req := &CanonicalRequest{
+		Method: "POST",
+		URL:    u,
+		Header: http.Header{
+			"X-Api-Key":    {"key"},
+			"Content-Type": {"application/json"},
+			"X-Multi":      {"a", "b"},
+		},
+		Body: []byte(`{"jsonrpc":"2.0","id":1,"method":"test"}`),
+	}
+
+	expected := "POST\n" +
+		"/rpc/v1?region=eu\n" +
+		"content-type:application/json\n" +
+		"x-api-key:key\n" +
+		"x-multi:a,b\n" +
+		"\n" +
+		`{"jsonrpc":"2.0","id":1,"method":"test"}`
+	if got := req.String(); got != expected {
+		t.Errorf("expected canonical string: %q, got: %q", expected, got)
+	}
+}
+
+func TestHTTPTransportCanonicalSigner(t *testing.T) {
+	secret := []byte("test-secret")
+	sign := func(s string) string {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(s))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		canonical := "POST\n" +
+			r.URL.RequestURI() + "\n" +
+			"content-type:" + r.Header.Get("Content-Type") + "\n" +
+			"x-api-key:" + r.Header.Get("X-Api-Key") + "\n" +
+			"x-body-signature:" + r.Header.Get("X-Body-Signature") + "\n" +
+			"\n" + string(body)
+		if got, want := r.Header.Get("X-Signature"), sign(canonical); got != want {
+			t.Errorf("expected X-Signature: %s, got: %s", want, got)
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"ok"}`))
+	}))
+	defer server.Close()
+
+	input := func() *SendRequestInput {
+		return &SendRequestInput{
+			Requests: []*JSONRPCRequest{{Version: "2.0", ID: NewID(1), Method: "test.method"}},
+		}
+	}
+
+	t.Run("signs method, URL, headers and body", func(t *testing.T) {
+		var seen *CanonicalRequest
+		transport := NewHTTPTransport(server.URL+"/rpc?v=1",
+			WithHTTPHeaders(map[string]string{"X-Api-Key": "key"}),
+			WithRequestSigner(func(body []byte) (string, string, error) {
+				return "X-Body-Signature", "body-sig", nil
+			}),
+			WithCanonicalSigner(func(req *CanonicalRequest) (string, string, error) {
+				seen = req
+				return "X-Signature", sign(req.String()), nil
+			}),
+		)
+
+		if _, err := transport.SendRequest(context.Background(), input()); err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+		if seen.URL.Path != "/rpc" || seen.URL.RawQuery != "v=1" {
+			t.Errorf("expected URL: /rpc?v=1, got: %s", seen.URL.RequestURI())
+		}
+		if seen.Header.Get("X-Body-Signature") != "body-sig" {
+			t.Errorf("expected body signature header to be visible, got: %v", seen.Header)
+		}
+	})
+
+	t.Run("signer error", func(t *testing.T) {
+		signErr := errors.New("sign error")
+		transport := NewHTTPTransport(server.URL, WithCanonicalSigner(func(req *CanonicalRequest) (string, string, error) {
+			return "", "", signErr
+		}))
+
+		_, err := transport.SendRequest(context.Background(), input())
+
+		var marshalErr *MarshalError
+		if !errors.As(err, &marshalErr) {
+			t.Fatalf("expected error type: *MarshalError, got: %T", err)
+		}
+		if !errors.Is(err, signErr) {
+			t.Errorf("expected wrapped signer error, got: %v", err)
+		}
+	})
+}
+
+type trackingBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *trackingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestDrainAndClose(t *testing.T) {
+	t.Run("drains remaining body", func(t *testing.T) {
+		body := &trackingBody{Reader: strings.NewReader(`{"error":"bad gateway"}`)}
+		drainAndClose(body)
+
+		if !body.closed {
+			t.Error("expected body to be closed")
+		}
+		if n, _ := body.Read(make([]byte, 1)); n != 0 {
+			t.Errorf("expected body to be fully drained, %d bytes left", n)
+		}
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		reader := strings.NewReader(strings.Repeat("x", maxDrainBytes+10))
+		body := &trackingBody{Reader: reader}
+		drainAndClose(body)
+
+		if !body.closed {
+			t.Error("expected body to be closed")
+		}
+		if reader.Len() != 10 {
+			t.Errorf("expected 10 bytes left unread, got: %d", reader.Len())
+		}
+	})
+}
+
+func TestHTTPTransportConnectionReuse(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		checkFn func(t *testing.T, err error)
+	}{
+		{
+			name:   "non-200 status",
+			status: http.StatusBadGateway,
+			body:   strings.Repeat("upstream unavailable ", 1000),
+			checkFn: func(t *testing.T, err error) {
+				var statusErr *StatusCodeError
+				if !errors.As(err, &statusErr) {
+					t.Fatalf("expected error type: *StatusCodeError, got: %T", err)
+				}
+			},
+		},
+		{
+			name:   "decode error",
+			status: http.StatusOK,
+			body:   `{"jsonrpc":"2.0","id":1,"result":` + strings.Repeat("x", 10000) + `}`,
+			checkFn: func(t *testing.T, err error) {
+				var unmarshalErr *UnmarshalError
+				if !errors.As(err, &unmarshalErr) {
+					t.Fatalf("expected error type: *UnmarshalError, got: %T", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			newConns := 0
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+				if state == http.StateNew {
+					mu.Lock()
+					newConns++
+					mu.Unlock()
+				}
+			}
+			server.Start()
+			defer server.Close()
+
+			transport := NewHTTPTransport(server.URL, WithHTTPClient(server.Client()))
+			for i := 0; i < 5; i++ {
+				_, err := transport.SendRequest(context.Background(), &SendRequestInput{
+					Requests: []*JSONRPCRequest{{Version: "2.0", ID: NewID(1), Method: "test.method"}},
+				})
+				tt.checkFn(t, err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if newConns != 1 {
+				t.Errorf("expected 1 connection to be reused, got: %d connections", newConns)
+			}
+		})
+	}
+}
+
+func TestHTTPTransportDecodeErrorBodies(t *testing.T) {
+	newServer := func(status int, body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			w.Write([]byte(body))
+		}))
+	}
+	input := func() *SendRequestInput {
+		return &SendRequestInput{
+			Requests: []*JSONRPCRequest{{Version: "2.0", ID: NewID(1), Method: "test.method"}},
+		}
+	}
+
+	t.Run("JSON-RPC error body", func(t *testing.T) {
+		server := newServer(http.StatusBadRequest, `{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"Invalid params","data":"name"}}`)
+		defer server.Close()
+
+		transport := NewHTTPTransport(server.URL, WithDecodeErrorBodies())
+		_, err := transport.SendRequest(context.Background(), input())
+
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) {
+			t.Fatalf("expected error type: *RPCError, got: %T", err)
+		}
+		if rpcErr.Method != "test.method" || rpcErr.Code != -32602 || rpcErr.Message != "Invalid params" {
+			t.Errorf("unexpected error fields: %+v", rpcErr)
+		}
+	})
+
+	t.Run("non JSON body", func(t *testing.T) {
+		server := newServer(http.StatusBadGateway, `<html>Bad Gateway</html>`)
+		defer server.Close()
+
+		transport := NewHTTPTransport(server.URL, WithDecodeErrorBodies())
+		_, err := transport.SendRequest(context.Background(), input())
+
+		var statusErr *StatusCodeError
+		if !errors.As(err, &statusErr) {
+			t.Fatalf("expected error type: *StatusCodeError, got: %T", err)
+		}
+		if statusErr.StatusCode != http.StatusBadGateway {
+			t.Errorf("expected status code: %d, got: %d", http.StatusBadGateway, statusErr.StatusCode)
+		}
+	})
+
+	t.Run("JSON body without error", func(t *testing.T) {
+		server := newServer(http.StatusInternalServerError, `{"jsonrpc":"2.0","id":1,"result":"ok"}`)
+		defer server.Close()
+
+		transport := NewHTTPTransport(server.URL, WithDecodeErrorBodies())
+		_, err := transport.SendRequest(context.Background(), input())
+
+		var statusErr *StatusCodeError
+		if !errors.As(err, &statusErr) {
+			t.Fatalf("expected error type: *StatusCodeError, got: %T", err)
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		server := newServer(http.StatusBadRequest, `{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"Invalid params"}}`)
+		defer server.Close()
+
+		transport := NewHTTPTransport(server.URL)
+		_, err := transport.SendRequest(context.Background(), input())
+
+		var statusErr *StatusCodeError
+		if !errors.As(err, &statusErr) {
+			t.Fatalf("expected error type: *StatusCodeError, got: %T", err)
+		}
+	})
+
+	t.Run("client reports RPCError with request ID", func(t *testing.T) {
+		server := newServer(http.StatusBadRequest, `{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"Invalid params"}}`)
+		defer server.Close()
+
+		client := NewClient(NewHTTPTransport(server.URL, WithDecodeErrorBodies()))
+		err := client.Invoke(context.Background(), &Invoke[[]int, int]{Name: "test.method", Request: []int{1}})
+
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) {
+			t.Fatalf("expected error type: *RPCError, got: %T", err)
+		}
+		if rpcErr.ID == nil || rpcErr.ID.String() != "1" {
+			t.Errorf("expected ID: 1, got: %v", rpcErr.ID)
+		}
+	})
+}
+
+func TestHTTPTransportResponseIDField(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		if body[0] == '[' {
+			var reqs []JSONRPCRequest
+			json.Unmarshal(body, &reqs)
+			// Answer in reverse order so that matching relies on the ID
+			w.Write([]byte(`[{"jsonrpc":"2.0","reqId":` + reqs[1].ID.String() + `,"result":"second"},` +
+				`{"jsonrpc":"2.0","reqId":` + reqs[0].ID.String() + `,"result":"first"}]`))
+			return
+		}
+		var req JSONRPCRequest
+		json.Unmarshal(body, &req)
+		w.Write([]byte(`{"jsonrpc":"2.0","reqId":` + req.ID.String() + `,"result":"single"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(NewHTTPTransport(server.URL, WithResponseIDField("reqId")))
+
+	t.Run("single request", func(t *testing.T) {
+		transport := NewHTTPTransport(server.URL, WithResponseIDField("reqId"))
+		output, err := transport.SendRequest(context.Background(), &SendRequestInput{
+			Requests: []*JSONRPCRequest{{Version: "2.0", ID: NewID(7), Method: "test"}},
+		})
+		if err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+		if !output.Responses[0].ID.Equal(NewID(7)) {
+			t.Errorf("expected ID: 7, got: %v", output.Responses[0].ID)
+		}
+	})
+
+	t.Run("batch matching", func(t *testing.T) {
+		first := &Invoke[[]int, string]{Name: "first", Request: []int{1}}
+		second := &Invoke[[]int, string]{Name: "second", Request: []int{2}}
+		if err := client.InvokeBatch(context.Background(), []MethodCaller{first, second}); err != nil {
+			t.Fatalf("InvokeBatch error: %v", err)
+		}
+		if first.Response != "first" || second.Response != "second" {
+			t.Errorf("expected responses: first and second, got: %s and %s", first.Response, second.Response)
+		}
+	})
+
+	t.Run("default field misses custom IDs", func(t *testing.T) {
+		client := NewClient(NewHTTPTransport(server.URL))
+		first := &Invoke[[]int, string]{Name: "first", Request: []int{1}}
+		second := &Invoke[[]int, string]{Name: "second", Request: []int{2}}
+		err := client.InvokeBatch(context.Background(), []MethodCaller{first, second})
+
+		var missingErr *MissingResponseError
+		if !errors.As(err, &missingErr) {
+			t.Fatalf("expected error type: *MissingResponseError, got: %T", err)
+		}
+	})
+}
+
+func TestRenameIDField(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected string
+	}{
+		{name: "renamed", raw: `{"reqId":1,"result":"ok"}`, expected: `{"id":1,"result":"ok"}`},
+		{name: "replaces standard id", raw: `{"id":null,"reqId":"a"}`, expected: `{"id":"a"}`},
+		{name: "missing field", raw: `{"id":1}`, expected: `{"id":1}`},
+		{name: "not an object", raw: `"garbage"`, expected: `"garbage"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renameIDField(json.RawMessage(tt.raw), "reqId")
+			if string(got) != tt.expected {
+				t.Errorf("expected: %s, got: %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestHTTPTransportIndentedRequests(t *testing.T) {
+	var got []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = io.ReadAll(r.Body)
+		if len(got) > 0 && got[0] == '[' {
+			w.Write([]byte(`[{"jsonrpc":"2.0","id":1,"result":"ok"}]`))
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"ok"}`))
+	}))
+	defer server.Close()
+
+	request := &JSONRPCRequest{Version: "2.0", ID: NewID(1), Method: "test.method", Params: []int{1}}
+
+	t.Run("single request", func(t *testing.T) {
+		transport := NewHTTPTransport(server.URL, WithIndentedRequests("", "  "))
+		if _, err := transport.SendRequest(context.Background(), &SendRequestInput{Requests: []*JSONRPCRequest{request}}); err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+
+		expected, _ := json.MarshalIndent(request, "", "  ")
+		if !bytes.Equal(got, expected) {
+			t.Errorf("expected body:\n%s\ngot:\n%s", expected, got)
+		}
+	})
+
+	t.Run("batch request", func(t *testing.T) {
+		transport := NewHTTPTransport(server.URL, WithIndentedRequests(">", "\t"))
+		input := &SendRequestInput{Requests: []*JSONRPCRequest{request}, Batch: true}
+		if _, err := transport.SendRequest(context.Background(), input); err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+
+		expected, _ := json.MarshalIndent(input.Requests, ">", "\t")
+		if !bytes.Equal(got, expected) {
+			t.Errorf("expected body:\n%s\ngot:\n%s", expected, got)
+		}
+	})
+
+	t.Run("compact by default", func(t *testing.T) {
+		transport := NewHTTPTransport(server.URL)
+		if _, err := transport.SendRequest(context.Background(), &SendRequestInput{Requests: []*JSONRPCRequest{request}}); err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+
+		expected, _ := json.Marshal(request)
+		if !bytes.Equal(got, expected) {
+			t.Errorf("expected body: %s, got: %s", expected, got)
+		}
+	})
+}
+
+func TestDecodeNotificationReplies(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected int
+	}{
+		{name: "empty", body: "", expected: 0},
+		{name: "whitespace", body: " \n", expected: 0},
+		{name: "single ack", body: `{"jsonrpc":"2.0","result":null}`, expected: 1},
+		{name: "array", body: `[{"jsonrpc":"2.0","result":1},{"jsonrpc":"2.0","result":2}]`, expected: 2},
+		{name: "empty array", body: `[]`, expected: 0},
+		{name: "not JSON", body: `OK`, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			responses := decodeNotificationReplies(strings.NewReader(tt.body))
+			if len(responses) != tt.expected {
+				t.Errorf("expected %d responses, got: %d", tt.expected, len(responses))
+			}
+		})
+	}
+}
+
+func TestHTTPTransportMaxRequestBytes(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		body, _ := io.ReadAll(r.Body)
+		if body[0] == '[' {
+			w.Write([]byte(`[{"jsonrpc":"2.0","id":1,"result":"ok"},{"jsonrpc":"2.0","id":2,"result":"ok"}]`))
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"ok"}`))
+	}))
+	defer server.Close()
+
+	request := &JSONRPCRequest{Version: "2.0", ID: NewID(1), Method: "upload", Params: []string{strings.Repeat("x", 100)}}
+	encoded, _ := json.Marshal(request)
+	size := len(encoded)
+
+	t.Run("at the limit", func(t *testing.T) {
+		transport := NewHTTPTransport(server.URL, WithMaxRequestBytes(size))
+		if _, err := transport.SendRequest(context.Background(), &SendRequestInput{Requests: []*JSONRPCRequest{request}}); err != nil {
+			t.Errorf("expected no error, got: %v", err)
+		}
+	})
+
+	t.Run("one byte over the limit", func(t *testing.T) {
+		requests = 0
+		transport := NewHTTPTransport(server.URL, WithMaxRequestBytes(size-1))
+		_, err := transport.SendRequest(context.Background(), &SendRequestInput{Requests: []*JSONRPCRequest{request}})
+
+		var tooLarge *RequestTooLargeError
+		if !errors.As(err, &tooLarge) {
+			t.Fatalf("expected error type: *RequestTooLargeError, got: %T", err)
+		}
+		if tooLarge.Method != "upload" || tooLarge.Size != size || tooLarge.Limit != size-1 || tooLarge.Batch {
+			t.Errorf("unexpected error fields: %+v", tooLarge)
+		}
+		if requests != 0 {
+			t.Errorf("expected nothing to be sent, got: %d requests", requests)
+		}
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		second := *request
+		second.ID = NewID(2)
+		transport := NewHTTPTransport(server.URL, WithMaxRequestBytes(size+1))
+		_, err := transport.SendRequest(context.Background(), &SendRequestInput{
+			Requests: []*JSONRPCRequest{request, &second},
+			Batch:    true,
+		})
+
+		var tooLarge *RequestTooLargeError
+		if !errors.As(err, &tooLarge) {
+			t.Fatalf("expected error type: *RequestTooLargeError, got: %T", err)
+		}
+		if !tooLarge.Batch || !strings.Contains(err.Error(), "splitting the batch") {
+			t.Errorf("expected a batch error suggesting a split, got: %v", err)
+		}
+	})
+
+	t.Run("client reports the request ID", func(t *testing.T) {
+		client := NewClient(NewHTTPTransport(server.URL, WithMaxRequestBytes(10)))
+		err := client.Invoke(context.Background(), &Invoke[[]string, string]{Name: "upload", Request: []string{"data"}})
+
+		expected := "rpc: request too large [upload id=1]: "
+		if err == nil || !strings.HasPrefix(err.Error(), expected) {
+			t.Errorf("expected error starting with: %s, got: %v", expected, err)
+		}
+	})
+}
+
+func TestHTTPTransportWithPath(t *testing.T) {
+	var requestURI string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestURI = r.URL.RequestURI()
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"ok"}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		baseURL  string
+		path     string
+		expected string
+	}{
+		{name: "host only", baseURL: server.URL, path: "rpc", expected: "/rpc"},
+		{name: "leading slash", baseURL: server.URL, path: "/rpc", expected: "/rpc"},
+		{name: "both slashes", baseURL: server.URL + "/", path: "/rpc", expected: "/rpc"},
+		{name: "repeated slashes", baseURL: server.URL + "//", path: "//rpc", expected: "/rpc"},
+		{name: "base path", baseURL: server.URL + "/api", path: "rpc/v1", expected: "/api/rpc/v1"},
+		{name: "trailing slash kept", baseURL: server.URL + "/api/", path: "/rpc/", expected: "/api/rpc/"},
+		{name: "query kept", baseURL: server.URL + "/api?key=1", path: "/rpc", expected: "/api/rpc?key=1"},
+	}
+
+	request := &JSONRPCRequest{Version: "2.0", ID: NewID(1), Method: "test"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := NewHTTPTransport(tt.baseURL, WithPath(tt.path))
+			if _, err := transport.SendRequest(context.Background(), &SendRequestInput{Requests: []*JSONRPCRequest{request}}); err != nil {
+				t.Fatalf("SendRequest error: %v", err)
+			}
+			if requestURI != tt.expected {
+				t.Errorf("expected path: %s, got: %s", tt.expected, requestURI)
+			}
+		})
+	}
+
+	t.Run("joined onto a per-call endpoint", func(t *testing.T) {
+		transport := NewHTTPTransport("http://unused.invalid", WithPath("/rpc"))
+		ctx := WithEndpoint(context.Background(), server.URL+"/tenant")
+		if _, err := transport.SendRequest(ctx, &SendRequestInput{Requests: []*JSONRPCRequest{request}}); err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+		if requestURI != "/tenant/rpc" {
+			t.Errorf("expected path: /tenant/rpc, got: %s", requestURI)
+		}
+	})
+
+	t.Run("invalid base URL", func(t *testing.T) {
+		transport := NewHTTPTransport("http://[::1", WithPath("/rpc"))
+		_, err := transport.SendRequest(context.Background(), &SendRequestInput{Requests: []*JSONRPCRequest{request}})
+
+		var invalidErr *InvalidRequestError
+		if !errors.As(err, &invalidErr) {
+			t.Fatalf("expected error type: *InvalidRequestError, got: %T", err)
+		}
+	})
+}
+
+func TestHTTPTransportWithMethodPath(t *testing.T) {
+	var requestURI string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestURI = r.URL.RequestURI()
+		body, _ := io.ReadAll(r.Body)
+		if bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+			_, _ = w.Write([]byte(`[{"jsonrpc":"2.0","id":1,"result":"ok"},{"jsonrpc":"2.0","id":2,"result":"ok"}]`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"ok"}`))
+	}))
+	defer server.Close()
+
+	byMethod := func(method string) string {
+		if method == "status" {
+			return ""
+		}
+		return strings.ReplaceAll(method, ".", "/")
+	}
+	send := func(transport *HTTPTransport, methods ...string) error {
+		input := &SendRequestInput{Batch: len(methods) > 1}
+		for i, method := range methods {
+			input.Requests = append(input.Requests, &JSONRPCRequest{Version: "2.0", ID: NewID(i + 1), Method: method})
+		}
+		_, err := transport.SendRequest(context.Background(), input)
+		return err
+	}
+
+	t.Run("single request", func(t *testing.T) {
+		if err := send(NewHTTPTransport(server.URL, WithMethodPath(byMethod)), "math.add"); err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+		if requestURI != "/math/add" {
+			t.Errorf("expected path: /math/add, got: %s", requestURI)
+		}
+	})
+
+	t.Run("after WithPath", func(t *testing.T) {
+		if err := send(NewHTTPTransport(server.URL, WithPath("/rpc/"), WithMethodPath(byMethod)), "add"); err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+		if requestURI != "/rpc/add" {
+			t.Errorf("expected path: /rpc/add, got: %s", requestURI)
+		}
+	})
+
+	t.Run("empty path leaves the URL unchanged", func(t *testing.T) {
+		if err := send(NewHTTPTransport(server.URL+"/rpc", WithMethodPath(byMethod)), "status"); err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+		if requestURI != "/rpc" {
+			t.Errorf("expected path: /rpc, got: %s", requestURI)
+		}
+	})
+
+	t.Run("batch with one path", func(t *testing.T) {
+		if err := send(NewHTTPTransport(server.URL, WithMethodPath(func(string) string { return "rpc" })), "add", "sub"); err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+		if requestURI != "/rpc" {
+			t.Errorf("expected path: /rpc, got: %s", requestURI)
+		}
+	})
+
+	t.Run("batch with mixed paths", func(t *testing.T) {
+		requestURI = ""
+		err := send(NewHTTPTransport(server.URL, WithMethodPath(byMethod)), "math.add", "math.sub")
+
+		var invalidErr *InvalidRequestError
+		if !errors.As(err, &invalidErr) {
+			t.Fatalf("expected error type: *InvalidRequestError, got: %T", err)
+		}
+		if requestURI != "" {
+			t.Errorf("expected nothing to be sent, got a request to: %s", requestURI)
+		}
+	})
+}
+
+func TestHTTPTransportHeaderFromContext(t *testing.T) {
+	type requestIDKey struct{}
+	type tenantIDKey struct{}
+
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"ok"}`))
+	}))
+	defer server.Close()
+
+	fromKey := func(key any) func(context.Context) (string, bool) {
+		return func(ctx context.Context) (string, bool) {
+			value, ok := ctx.Value(key).(string)
+			return value, ok
+		}
+	}
+	transport := NewHTTPTransport(server.URL,
+		WithHTTPHeaders(map[string]string{"X-Tenant-ID": "default"}),
+		WithHeaderFromContext("X-Request-ID", fromKey(requestIDKey{})),
+		WithHeaderFromContext("X-Tenant-ID", fromKey(tenantIDKey{})),
+	)
+	request := &JSONRPCRequest{Version: "2.0", ID: NewID(1), Method: "test"}
+
+	t.Run("present values", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), requestIDKey{}, "req-1")
+		ctx = context.WithValue(ctx, tenantIDKey{}, "tenant-a")
+		if _, err := transport.SendRequest(ctx, &SendRequestInput{Requests: []*JSONRPCRequest{request}}); err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+		if got := header.Get("X-Request-ID"); got != "req-1" {
+			t.Errorf("expected X-Request-ID: req-1, got: %s", got)
+		}
+		if got := header.Get("X-Tenant-ID"); got != "tenant-a" {
+			t.Errorf("expected X-Tenant-ID: tenant-a, got: %s", got)
+		}
+	})
+
+	t.Run("absent values", func(t *testing.T) {
+		if _, err := transport.SendRequest(context.Background(), &SendRequestInput{Requests: []*JSONRPCRequest{request}}); err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+		if _, ok := header["X-Request-Id"]; ok {
+			t.Errorf("expected no X-Request-ID header, got: %s", header.Get("X-Request-ID"))
+		}
+		if got := header.Get("X-Tenant-ID"); got != "default" {
+			t.Errorf("expected the static X-Tenant-ID: default, got: %s", got)
+		}
+	})
+}
+
+func TestHTTPTransportTLSInfo(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"ok"}`))
+	})
+	request := &JSONRPCRequest{Version: "2.0", ID: NewID(1), Method: "test"}
+
+	t.Run("TLS endpoint", func(t *testing.T) {
+		server := httptest.NewTLSServer(handler)
+		defer server.Close()
+
+		var method string
+		var state *tls.ConnectionState
+		transport := NewHTTPTransport(server.URL,
+			WithHTTPClient(server.Client()),
+			WithTLSInfo(func(m string, s *tls.ConnectionState) {
+				method, state = m, s
+			}),
+		)
+		if _, err := transport.SendRequest(context.Background(), &SendRequestInput{Requests: []*JSONRPCRequest{request}}); err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+		if method != "test" {
+			t.Errorf("expected method: test, got: %s", method)
+		}
+		if state == nil || !state.HandshakeComplete || state.Version == 0 || state.CipherSuite == 0 {
+			t.Fatalf("expected a completed TLS handshake, got: %+v", state)
+		}
+	})
+
+	t.Run("plain HTTP endpoint", func(t *testing.T) {
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		called := false
+		transport := NewHTTPTransport(server.URL, WithTLSInfo(func(m string, s *tls.ConnectionState) {
+			called = true
+			if s != nil {
+				t.Errorf("expected no TLS state, got: %+v", s)
+			}
+		}))
+		if _, err := transport.SendRequest(context.Background(), &SendRequestInput{Requests: []*JSONRPCRequest{request}}); err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+		if !called {
+			t.Errorf("expected the callback to be called")
+		}
+	})
+}
+
+// recordingRoundTripper answers every request and keeps it, so its body can be read again
+// after SendRequest has returned
+type recordingRoundTripper struct {
+	requests []*http.Request
+}
+
+func (rt *recordingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.requests = append(rt.requests, req)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(`{"jsonrpc":"2.0","id":1,"result":"ok"}`)),
+	}, nil
+}
+
+func TestHTTPTransportRequestBodyOutlivesCall(t *testing.T) {
+	rt := &recordingRoundTripper{}
+	transport := NewHTTPTransport("http://example.com/rpc", WithHTTPClient(&http.Client{Transport: rt}))
+
+	send := func(param string) {
+		input := &SendRequestInput{Requests: []*JSONRPCRequest{{Version: "2.0", ID: NewID(1), Method: "test", Params: []string{param}}}}
+		if _, err := transport.SendRequest(context.Background(), input); err != nil {
+			t.Fatalf("SendRequest error: %v", err)
+		}
+	}
+	send("first")
+	// Reuses the pooled encoding buffer of the first call
+	send("second")
+
+	// The HTTP client may replay a body through GetBody after the call, e.g. on a redirect
+	body, err := rt.requests[0].GetBody()
+	if err != nil {
+		t.Fatalf("GetBody error: %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	if expected := `{"jsonrpc":"2.0","id":1,"method":"test","params":["first"]}`; string(data) != expected {
+		t.Errorf("expected body: %s, got: %s", expected, data)
+	}
+}
+
+func TestNewTransportForURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantErr string
+	}{
+		{name: "http", url: "http://localhost:8080/rpc"},
+		{name: "https", url: "HTTPS://example.com/rpc"},
+		{name: "websocket", url: "wss://example.com/rpc", wantErr: `scheme "wss" is not supported`},
+		{name: "unix socket", url: "unix:///run/rpc.sock", wantErr: `scheme "unix" is not supported`},
+		{name: "tcp", url: "tcp://localhost:9000", wantErr: `scheme "tcp" is not supported`},
+		{name: "unknown scheme", url: "ftp://example.com", wantErr: `unknown scheme "ftp"`},
+		{name: "no scheme", url: "localhost:8080", wantErr: "unknown scheme"},
+		{name: "no host", url: "http:///rpc", wantErr: "has no host"},
+		{name: "invalid", url: "http://[::1", wantErr: "invalid transport URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := NewTransportForURL(tt.url, WithHTTPHeaders(map[string]string{"X-Test": "1"}))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error containing: %s, got: %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewTransportForURL error: %v", err)
+			}
+			httpTransport, ok := transport.(*HTTPTransport)
+			if !ok {
+				t.Fatalf("expected transport type: *HTTPTransport, got: %T", transport)
+			}
+			if httpTransport.baseURL != tt.url || httpTransport.headers["X-Test"] != "1" {
+				t.Errorf("expected the URL and options to be applied, got: %s %v", httpTransport.baseURL, httpTransport.headers)
+			}
+		})
+	}
+}
+
+func TestHTTPTransportBatchSingleObjectResponse(t *testing.T) {
+	var reply string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(reply))
+	}))
+	defer server.Close()
+	client := NewClient(NewHTTPTransport(server.URL))
+
+	t.Run("batch-level error", func(t *testing.T) {
+		reply = `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Batch not supported"}}`
+		first := &Invoke[[]int, string]{Name: "first", Request: []int{1}}
+		second := &Invoke[[]int, string]{Name: "second", Request: []int{2}}
+		err := client.InvokeBatch(context.Background(), []MethodCaller{first, second})
+
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) {
+			t.Fatalf("expected error type: *RPCError, got: %T (%v)", err, err)
+		}
+		if rpcErr.Code != -32600 || rpcErr.Message != "Batch not supported" {
+			t.Errorf("expected the batch error, got: %v", rpcErr)
+		}
+	})
+
+	t.Run("object answering one request", func(t *testing.T) {
+		reply = ` {"jsonrpc":"2.0","id":1,"result":"one"}`
+		client := NewClient(NewHTTPTransport(server.URL))
+		first := &Invoke[[]int, string]{Name: "first", Request: []int{1}}
+		second := &Invoke[[]int, string]{Name: "second", Request: []int{2}}
+		err := client.InvokeBatch(context.Background(), []MethodCaller{first, second})
+
+		var missingErr *MissingResponseError
+		if !errors.As(err, &missingErr) || missingErr.Method != "second" {
+			t.Fatalf("expected a missing response for second, got: %v", err)
+		}
+		if first.Response != "one" {
+			t.Errorf("expected response: one, got: %s", first.Response)
+		}
+	})
+
+	t.Run("null ID request keeps the response", func(t *testing.T) {
+		reply = `{"jsonrpc":"2.0","id":null,"error":{"code":-32000,"message":"failed"}}`
+		request := &Invoke[[]int, string]{ID: NewNullID(), Name: "null", Request: []int{1}}
+		err := client.InvokeBatch(context.Background(), []MethodCaller{request})
+
+		var rpcErr *RPCError
+		if !errors.As(err, &rpcErr) || rpcErr.Method != "null" {
+			t.Errorf("expected the error for the null ID request, got: %v", err)
+		}
+	})
+}